@@ -0,0 +1,154 @@
+// bot 包提供与平台无关的机器人接口。
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/kvii/bot/dingtalk"
+	"github.com/kvii/bot/feishu"
+	"github.com/kvii/bot/wx"
+)
+
+// 机器人。wx.BotClient 与 feishu.BotClient 都实现了该接口。
+type Bot interface {
+	SendText(ctx context.Context, msg string) error // 发送文本信息
+}
+
+var (
+	_ Bot = wx.BotClient{}
+	_ Bot = feishu.BotClient{}
+	_ Bot = dingtalk.BotClient{}
+)
+
+// 支持提醒所有人的机器人。内置平台的客户端都实现了该接口。
+type MentionAller interface {
+	SendTextMentionAll(ctx context.Context, msg string) error // 发送文本信息，并提醒所有人
+}
+
+var (
+	_ MentionAller = wx.BotClient{}
+	_ MentionAller = feishu.BotClient{}
+	_ MentionAller = dingtalk.BotClient{}
+)
+
+// 机器人配置
+type Options struct {
+	Client  *http.Client // 底层 http client。不填则使用默认值。
+	Logger  *slog.Logger // 日志 logger。不填则使用默认值。
+	BaseURL string       // 接口基础地址。不填则使用默认值。
+	Token   string       // 机器人令牌。企业微信为 key，飞书为 token，钉钉为 access_token。
+	Secret  string       // 签名密钥。企业微信不使用。
+}
+
+// 机器人构造函数
+type Factory func(opts Options) (Bot, error)
+
+// 预定义错误
+var (
+	ErrUnknownPlatform       = errors.New("bot: unknown platform")        // 未注册的平台
+	ErrMentionAllUnsupported = errors.New("bot: mention all unsupported") // 不支持提醒所有人
+)
+
+// 内置平台名称
+const (
+	PlatformWX       = "wx"       // 企业微信
+	PlatformFeishu   = "feishu"   // 飞书
+	PlatformDingTalk = "dingtalk" // 钉钉
+)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		PlatformWX: func(opts Options) (Bot, error) {
+			return wx.BotClient{
+				Client:  opts.Client,
+				Logger:  opts.Logger,
+				BaseURL: opts.BaseURL,
+				Key:     opts.Token,
+			}, nil
+		},
+		PlatformFeishu: func(opts Options) (Bot, error) {
+			return feishu.BotClient{
+				Client:  opts.Client,
+				Logger:  opts.Logger,
+				BaseURL: opts.BaseURL,
+				Token:   opts.Token,
+				Secret:  opts.Secret,
+			}, nil
+		},
+		PlatformDingTalk: func(opts Options) (Bot, error) {
+			return dingtalk.BotClient{
+				Client:      opts.Client,
+				Logger:      opts.Logger,
+				BaseURL:     opts.BaseURL,
+				AccessToken: opts.Token,
+				Secret:      opts.Secret,
+			}, nil
+		},
+	}
+)
+
+// 注册平台。同名平台会被覆盖。
+func Register(name string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = f
+}
+
+// 返回已注册的平台名称，按字母排序。
+func Platforms() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 根据平台名称创建机器人。平台未注册时返回 [ErrUnknownPlatform]。
+func New(name string, opts Options) (Bot, error) {
+	mu.RLock()
+	f, ok := factories[name]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPlatform, name)
+	}
+	return f(opts)
+}
+
+// 返回指定平台提醒所有人的方式。
+// field 为承载提醒的字段，value 为该字段中填写的值：
+// 企业微信为 text.mentioned_list 中的 "@all"，飞书为文本内容中的 <at user_id="all"></at>，
+// 钉钉为 at.isAtAll 字段的 true。未知平台返回空字符串。
+func AtAll(platform string) (field, value string) {
+	switch platform {
+	case PlatformWX:
+		return "mentioned_list", wx.MentionAll
+	case PlatformFeishu:
+		return "text", feishu.AtAll()
+	case PlatformDingTalk:
+		return "isAtAll", "true"
+	default:
+		return "", ""
+	}
+}
+
+// 发送文本信息，并提醒所有人。
+// b 未实现 [MentionAller] 时返回 [ErrMentionAllUnsupported]。
+func SendTextMentionAll(ctx context.Context, b Bot, msg string) error {
+	m, ok := b.(MentionAller)
+	if !ok {
+		return ErrMentionAllUnsupported
+	}
+	return m.SendTextMentionAll(ctx, msg)
+}
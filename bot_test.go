@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kvii/bot/feishu"
+	"github.com/kvii/bot/wx"
+)
+
+func TestNew(t *testing.T) {
+	b, err := New(PlatformWX, Options{Token: "key"})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if c, ok := b.(wx.BotClient); !ok || c.Key != "key" {
+		t.Fatalf("unexpected bot: %#v", b)
+	}
+
+	b, err = New(PlatformFeishu, Options{Token: "token", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if c, ok := b.(feishu.BotClient); !ok || c.Token != "token" || c.Secret != "secret" {
+		t.Fatalf("unexpected bot: %#v", b)
+	}
+
+	_, err = New("unknown", Options{})
+	if !errors.Is(err, ErrUnknownPlatform) {
+		t.Fatalf("expect %v, got %v", ErrUnknownPlatform, err)
+	}
+}
+
+type fakeBot struct{ msgs *[]string }
+
+func (b fakeBot) SendText(ctx context.Context, msg string) error {
+	*b.msgs = append(*b.msgs, msg)
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	var msgs []string
+	Register("fake", func(opts Options) (Bot, error) {
+		return fakeBot{&msgs}, nil
+	})
+
+	b, err := New("fake", Options{})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	err = b.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if len(msgs) != 1 || msgs[0] != "测试" {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+}
+
+func TestAtAll(t *testing.T) {
+	testCases := []struct {
+		platform string // 平台名称
+		field    string // 预期字段
+		value    string // 预期值
+	}{
+		{platform: PlatformWX, field: "mentioned_list", value: "@all"},
+		{platform: PlatformFeishu, field: "text", value: `<at user_id="all"></at>`},
+		{platform: PlatformDingTalk, field: "isAtAll", value: "true"},
+		{platform: "unknown", field: "", value: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.platform, func(t *testing.T) {
+			field, value := AtAll(tc.platform)
+			if field != tc.field || value != tc.value {
+				t.Fatalf("expect (%q, %q), got (%q, %q)", tc.field, tc.value, field, value)
+			}
+		})
+	}
+}
+
+type fakeAller struct {
+	fakeBot
+	all *bool
+}
+
+func (b fakeAller) SendTextMentionAll(ctx context.Context, msg string) error {
+	*b.all = true
+	return b.SendText(ctx, msg)
+}
+
+func TestSendTextMentionAll(t *testing.T) {
+	var msgs []string
+	err := SendTextMentionAll(context.Background(), fakeBot{&msgs}, "测试")
+	if !errors.Is(err, ErrMentionAllUnsupported) {
+		t.Fatalf("expect %v, got %v", ErrMentionAllUnsupported, err)
+	}
+
+	var all bool
+	err = SendTextMentionAll(context.Background(), fakeAller{fakeBot{&msgs}, &all}, "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if !all || len(msgs) != 1 {
+		t.Fatalf("unexpected state: all=%v msgs=%v", all, msgs)
+	}
+}
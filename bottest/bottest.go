@@ -0,0 +1,10 @@
+// bottest 包提供编写机器人客户端测试时使用的辅助函数。
+package bottest
+
+import "github.com/kvii/bot/internal/errmatch"
+
+// ErrContains 返回一个用于判断错误信息是否包含指定字符串的错误对象。
+// 需要将其作为 errors.Is 的第一个参数使用，如 errors.Is(ErrContains("超时"), err)。
+func ErrContains(s string) error {
+	return errmatch.Contains(s)
+}
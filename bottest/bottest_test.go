@@ -0,0 +1,28 @@
+package bottest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrContains(t *testing.T) {
+	testCases := []struct {
+		name string // 测试项目
+		err  error  // 实际错误
+		want bool   // 预期结果
+	}{
+		{name: "contains", err: errors.New("响应异常: 93000"), want: true},
+		{name: "wrapped", err: fmt.Errorf("发送失败: %w", errors.New("响应异常")), want: true},
+		{name: "not contains", err: errors.New("超时"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := errors.Is(ErrContains("响应异常"), tc.err)
+			if got != tc.want {
+				t.Fatalf("expect %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package bottest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/kvii/bot/feishu"
+	"github.com/kvii/bot/wx"
+)
+
+// 模拟服务器使用的令牌
+const (
+	WXKey       = "ee556a46-a3a7-4978-a186-7e3181f29da9" // 企业微信机器人令牌
+	FeishuToken = "85d09ddb-5937-46e7-8628-d7959a93e3af" // 飞书机器人令牌
+)
+
+// 记录的请求
+type Request struct {
+	Method string      // 请求方法
+	Path   string      // 请求路径
+	Query  string      // 查询参数
+	Header http.Header // 请求头
+	Body   []byte      // 请求内容
+}
+
+// 模拟服务器。记录收到的请求，并按设置返回成功或指定的错误码。
+// 可以在多个 goroutine 间并发使用。
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	code     int    // 响应码，0 表示成功
+	msg      string // 异常信息
+	status   int    // 响应状态码
+}
+
+// 方法设置之后的请求返回的响应码与异常信息。code 为 0 时恢复为成功。
+func (s *Server) SetError(code int, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.code, s.msg = code, msg
+}
+
+// 方法设置之后的请求返回的响应状态码。status 为 0 时恢复为 200。
+func (s *Server) SetStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+// 方法返回已收到的请求的副本。
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// 方法清空已收到的请求。
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// 创建企业微信模拟服务器，以及指向该服务器的客户端。
+// 客户端的令牌为 [WXKey]，日志使用 wx.NopLogger。使用结束后需要调用 Close。
+func NewWXServer() (*Server, wx.BotClient) {
+	s := newServer("POST /cgi-bin/webhook/send", func(code int, msg string) string {
+		return fmt.Sprintf(`{"errcode":%d,"errmsg":%q}`, code, msg)
+	})
+	c := wx.BotClient{
+		Client:  s.Client(),
+		Logger:  wx.NopLogger(),
+		BaseURL: s.URL,
+		Key:     WXKey,
+	}
+	return s, c
+}
+
+// 创建飞书模拟服务器，以及指向该服务器的客户端。
+// 客户端的令牌为 [FeishuToken]，日志使用 feishu.NopLogger。使用结束后需要调用 Close。
+func NewFeishuServer() (*Server, feishu.BotClient) {
+	s := newServer("POST /open-apis/bot/v2/hook/{token}", func(code int, msg string) string {
+		return fmt.Sprintf(`{"code":%d,"data":{},"msg":%q}`, code, msg)
+	})
+	c := feishu.BotClient{
+		Client:  s.Client(),
+		Logger:  feishu.NopLogger(),
+		BaseURL: s.URL,
+		Token:   FeishuToken,
+	}
+	return s, c
+}
+
+// 函数创建在 pattern 上响应的模拟服务器，响应内容由 body 生成。
+func newServer(pattern string, body func(code int, msg string) string) *Server {
+	s := new(Server)
+	var mux http.ServeMux
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Header: r.Header.Clone(),
+			Body:   bs,
+		})
+		code, msg, status := s.code, s.msg, s.status
+		s.mu.Unlock()
+
+		if status != 0 && status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		if code == 0 {
+			msg = "ok"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body(code, msg)))
+	})
+	s.Server = httptest.NewServer(&mux)
+	return s
+}
@@ -0,0 +1,66 @@
+package bottest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kvii/bot/feishu"
+	"github.com/kvii/bot/wx"
+)
+
+func TestNewWXServer(t *testing.T) {
+	s, c := NewWXServer()
+	t.Cleanup(s.Close)
+
+	err := c.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	reqs := s.Requests()
+	if len(reqs) != 1 || !strings.Contains(string(reqs[0].Body), "测试") || !strings.Contains(reqs[0].Query, WXKey) {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+
+	s.SetError(93000, "invalid webhook url")
+	err = c.SendText(context.Background(), "测试")
+	if !errors.Is(err, wx.ErrWebhookInvalid) {
+		t.Fatalf("expect %v, got %v", wx.ErrWebhookInvalid, err)
+	}
+
+	s.SetError(0, "")
+	s.SetStatus(http.StatusBadGateway)
+	err = c.SendText(context.Background(), "测试")
+	var httpErr wx.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expect status error, got %v", err)
+	}
+
+	s.Reset()
+	if n := len(s.Requests()); n != 0 {
+		t.Fatalf("expect no requests, got %d", n)
+	}
+}
+
+func TestNewFeishuServer(t *testing.T) {
+	s, c := NewFeishuServer()
+	t.Cleanup(s.Close)
+
+	err := c.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	reqs := s.Requests()
+	if len(reqs) != 1 || !strings.HasSuffix(reqs[0].Path, FeishuToken) {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+
+	s.SetError(feishu.CodeSignMismatch, "sign match fail")
+	err = c.SendText(context.Background(), "测试")
+	expect := feishu.APIError{Code: feishu.CodeSignMismatch, Msg: "sign match fail"}
+	if !errors.Is(err, expect) {
+		t.Fatalf("expect %v, got %v", expect, err)
+	}
+}
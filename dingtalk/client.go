@@ -0,0 +1,242 @@
+package dingtalk
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 信息类型
+type MessageType = string
+
+const (
+	MessageTypeText     MessageType = "text"     // 文本信息类型
+	MessageTypeMarkdown MessageType = "markdown" // markdown 信息类型
+)
+
+// 信息
+type Message struct {
+	MsgType  MessageType      `json:"msgtype"`            // 信息类型
+	Text     *TextMessage     `json:"text,omitempty"`     // 文本信息
+	Markdown *MarkdownMessage `json:"markdown,omitempty"` // markdown 信息
+	At       *At              `json:"at,omitempty"`       // 被@的群成员信息
+}
+
+// 文本信息
+type TextMessage struct {
+	Content string `json:"content"` // 是	文本内容
+}
+
+// markdown 信息
+type MarkdownMessage struct {
+	Title string `json:"title"` // 是	首屏会话透出的展示内容
+	Text  string `json:"text"`  // 是	markdown 格式的消息内容
+}
+
+// 被@的群成员信息
+type At struct {
+	AtMobiles []string `json:"atMobiles,omitempty"` // 否	被@的群成员手机号
+	AtUserIDs []string `json:"atUserIds,omitempty"` // 否	被@的群成员 userId
+	IsAtAll   bool     `json:"isAtAll,omitempty"`   // 否	是否@所有人
+}
+
+// 发送响应
+type SendResponse struct {
+	ErrCode int    `json:"errcode"` // 错误码
+	ErrMsg  string `json:"errmsg"`  // 错误说明
+}
+
+// 接口错误。errcode 非 0 时返回。
+// 可以通过 errors.As 获取错误码。
+type APIError struct {
+	Code    int    // 错误码
+	Message string // 错误说明
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("响应异常: %d %s", e.Code, e.Message)
+}
+
+// 预定义错误
+var (
+	ErrNeedToken             = errors.New("dingtalk: need token")              // 需要提供令牌
+	ErrUnexpectedContentType = errors.New("dingtalk: unexpected content type") // 响应类型错误
+)
+
+// 钉钉机器人客户端
+type BotClient struct {
+	Client      *http.Client // 底层 http client。不填则使用默认值。
+	Logger      *slog.Logger // 日志 logger。不填则使用默认值。
+	BaseURL     string       // 接口基础地址。不填则使用默认值。
+	AccessToken string       // 机器人令牌。
+	Secret      string       // 加签密钥。不填则不进行签名。
+}
+
+// 发送文本信息。
+func (c BotClient) SendText(ctx context.Context, msg string) error {
+	c.logger().InfoContext(ctx, "发送文本消息")
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeText,
+		Text:    &TextMessage{Content: msg},
+	})
+}
+
+// 发送文本信息，并 @ 所有人。
+func (c BotClient) SendTextMentionAll(ctx context.Context, msg string) error {
+	c.logger().InfoContext(ctx, "发送文本消息", slog.Bool("atAll", true))
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeText,
+		Text:    &TextMessage{Content: msg},
+		At:      &At{IsAtAll: true},
+	})
+}
+
+// 发送 markdown 信息。
+// title 为首屏会话透出的展示内容。
+func (c BotClient) SendMarkdown(ctx context.Context, title, text string) error {
+	c.logger().InfoContext(ctx, "发送 Markdown 消息")
+
+	return c.send(ctx, Message{
+		MsgType:  MessageTypeMarkdown,
+		Markdown: &MarkdownMessage{Title: title, Text: text},
+	})
+}
+
+// 方法发送信息。
+func (c BotClient) Send(ctx context.Context, msg Message) error {
+	c.logger().InfoContext(ctx, "发送消息", slog.String("msgType", msg.MsgType))
+	return c.send(ctx, msg)
+}
+
+func (c BotClient) send(ctx context.Context, msg Message) error {
+	if c.AccessToken == "" {
+		c.logger().ErrorContext(ctx, "需要提供令牌")
+		return ErrNeedToken
+	}
+
+	u, err := url.Parse(c.baseURL())
+	if err != nil {
+		c.logger().ErrorContext(ctx, "URL 解析失败", slog.Any("err", err))
+		return err
+	}
+	u = u.JoinPath("/robot/send")
+	q := u.Query()
+	q.Set("access_token", c.AccessToken)
+	if c.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		q.Set("timestamp", timestamp)
+		q.Set("sign", sign(timestamp, c.Secret))
+	}
+	u.RawQuery = q.Encode()
+
+	bs, err := json.Marshal(msg)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(bs))
+	if err != nil {
+		c.logger().ErrorContext(ctx, "请求创建失败", slog.Any("err", err))
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		err = redactErr(err)
+		c.logger().ErrorContext(ctx, "请求发送失败", slog.Any("err", err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	bs, err = io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "响应读取失败", slog.Any("err", err))
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger().ErrorContext(ctx, "响应状态错误", slog.Int("status-code", resp.StatusCode), slog.Any("body", bytes.NewBuffer(bs)))
+		return fmt.Errorf("响应状态错误: %d", resp.StatusCode)
+	}
+	if mt := resp.Header.Get("Content-Type"); !isJSON(mt) {
+		c.logger().ErrorContext(ctx, "响应类型错误", slog.String("content-type", mt), slog.Any("body", bytes.NewBuffer(bs)))
+		return fmt.Errorf("%w: %s", ErrUnexpectedContentType, mt)
+	}
+
+	var data SendResponse
+	err = json.Unmarshal(bs, &data)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "响应解析失败", slog.Any("err", err), slog.Any("body", bytes.NewBuffer(bs)))
+		return err
+	}
+	if data.ErrCode != 0 {
+		c.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.ErrCode), slog.String("msg", data.ErrMsg))
+		return APIError{Code: data.ErrCode, Message: data.ErrMsg}
+	}
+
+	c.logger().InfoContext(ctx, "消息发送成功")
+	return nil
+}
+
+// 函数计算签名。
+// 签名为以 secret 为密钥，对 timestamp + "\n" + secret 进行 HmacSHA256 计算后的 Base64 编码。
+func sign(timestamp, secret string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// 函数将请求错误中地址包含的令牌与签名替换为 ***。
+// http.Client 返回的 *url.Error 在错误信息中带有完整的请求地址。
+func redactErr(err error) error {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	redacted := *ue
+	redacted.URL = redact(ue.URL)
+	return &redacted
+}
+
+// 函数将地址中 access_token 与 sign 参数的值替换为 ***。地址无法解析时去掉全部查询参数。
+func redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		before, _, _ := strings.Cut(rawURL, "?")
+		return before
+	}
+	q := u.Query()
+	for _, k := range []string{"access_token", "sign"} {
+		if v := q.Get(k); v != "" {
+			rawURL = strings.ReplaceAll(rawURL, url.QueryEscape(v), "***")
+		}
+	}
+	return rawURL
+}
+
+func (c BotClient) logger() *slog.Logger { return cmp.Or(c.Logger, slog.Default()) }
+func (c BotClient) client() *http.Client { return cmp.Or(c.Client, http.DefaultClient) }
+func (c BotClient) baseURL() string      { return cmp.Or(c.BaseURL, "https://oapi.dingtalk.com") }
+
+// 函数判断响应类型是否为 json。忽略大小写，兼容 text/json 与 charset 等参数。
+func isJSON(contentType string) bool {
+	mt := strings.ToLower(strings.TrimSpace(contentType))
+	return strings.HasPrefix(mt, "application/json") || strings.HasPrefix(mt, "text/json")
+}
@@ -0,0 +1,173 @@
+package dingtalk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBotClientSendText(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	var mux http.ServeMux
+	mux.HandleFunc("POST /robot/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		q := r.URL.Query()
+		switch q.Get("access_token") {
+		case "":
+			w.Write([]byte(`{"errcode":300001,"errmsg":"token is not exist"}`))
+		case "signed":
+			if q.Get("sign") != sign(q.Get("timestamp"), "SEC123") {
+				w.Write([]byte(`{"errcode":310000,"errmsg":"sign not match"}`))
+				return
+			}
+			w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		default:
+			w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	testCases := []struct {
+		name   string    // 测试项目
+		client BotClient // 客户端
+		err    error     // 预期错误
+	}{
+		{
+			name: "normal",
+			client: BotClient{
+				Client:      s.Client(),
+				Logger:      logger,
+				BaseURL:     s.URL,
+				AccessToken: "1a2b3c",
+			},
+			err: nil,
+		},
+		{
+			name: "empty token",
+			client: BotClient{
+				Client:  s.Client(),
+				Logger:  logger,
+				BaseURL: s.URL,
+			},
+			err: ErrNeedToken,
+		},
+		{
+			name: "signed",
+			client: BotClient{
+				Client:      s.Client(),
+				Logger:      logger,
+				BaseURL:     s.URL,
+				AccessToken: "signed",
+				Secret:      "SEC123",
+			},
+			err: nil,
+		},
+		{
+			name: "sign not match",
+			client: BotClient{
+				Client:      s.Client(),
+				Logger:      logger,
+				BaseURL:     s.URL,
+				AccessToken: "signed",
+				Secret:      "wrong",
+			},
+			err: APIError{Code: 310000, Message: "sign not match"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.client.SendText(context.Background(), "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendMarkdown(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	var got []byte
+	var mux http.ServeMux
+	mux.HandleFunc("POST /robot/send", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	client := BotClient{
+		Client:      s.Client(),
+		Logger:      logger,
+		BaseURL:     s.URL,
+		AccessToken: "1a2b3c",
+	}
+	err := client.SendMarkdown(context.Background(), "杭州天气", "#### 杭州天气\n> 9度，西北风1级")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	expect := `{"msgtype":"markdown","markdown":{"title":"杭州天气","text":"#### 杭州天气\n\u003e 9度，西北风1级"}}`
+	if string(got) != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestBotClient_RedactRequestError(t *testing.T) {
+	// 关闭后的服务端地址无法连接
+	s := httptest.NewServer(http.NotFoundHandler())
+	s.Close()
+
+	var buf bytes.Buffer
+	client := BotClient{
+		Client:      s.Client(),
+		Logger:      slog.New(slog.NewTextHandler(&buf, nil)),
+		BaseURL:     s.URL,
+		AccessToken: "SECRET_TOKEN",
+		Secret:      "SECRET_KEY",
+	}
+	err := client.SendText(context.Background(), "测试")
+	var ue *url.Error
+	if !errors.As(err, &ue) {
+		t.Fatalf("expect *url.Error, got %T", err)
+	}
+	for _, out := range []string{err.Error(), buf.String()} {
+		if strings.Contains(out, "SECRET_TOKEN") {
+			t.Fatalf("token should be redacted: %s", out)
+		}
+		if !strings.Contains(out, "sign=***") {
+			t.Fatalf("sign should be redacted: %s", out)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := sign("1577262236757", "SEC123")
+	expect := "Z/IOagKYTkrnYtxAsTKneRe0bzmlPCH3ZDJPTD2h9QA="
+	if got != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
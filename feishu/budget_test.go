@@ -0,0 +1,68 @@
+package feishu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBotClient_RetryBudget(t *testing.T) {
+	// 总是返回限流
+	var requests atomic.Int64
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":11232,"data":{},"msg":"frequency limited"}`))
+	}))
+
+	var exhausted atomic.Int64
+	client.Retry = RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	client.RetryBudget = NewRetryBudget(0, 4)
+	client.OnSend = func(msgType string, duration time.Duration, err error) {
+		if errors.Is(err, ErrRetryBudgetExhausted) {
+			exhausted.Add(1)
+		}
+	}
+
+	// 5 次发送各自允许 2 次重试，预算只够 4 次重试
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.SendText(context.Background(), "测试")
+		}()
+	}
+	wg.Wait()
+
+	if n := requests.Load(); n != 5+4 {
+		t.Fatalf("expect %d requests, got %d", 5+4, n)
+	}
+	if n := exhausted.Load(); n < 1 {
+		t.Fatalf("expect budget exhausted at least once, got %d", n)
+	}
+
+	// 预算耗尽后不再重试，错误中同时包含最后一次发送的错误
+	requests.Store(0)
+	err := client.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrRetryBudgetExhausted) || !isRateLimited(err) {
+		t.Fatalf("expect %v, got %v", ErrRetryBudgetExhausted, err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("expect 1 request, got %d", n)
+	}
+}
+
+func TestRetryBudget_Nil(t *testing.T) {
+	var b *RetryBudget
+	for range 3 {
+		if !b.Allow() {
+			t.Fatal("expect nil budget to allow")
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package feishu
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// 函数校验飞书回调请求的签名。
+// timestamp、nonce 与 signature 分别为请求头 X-Lark-Request-Timestamp、X-Lark-Request-Nonce 与 X-Lark-Signature 的值，
+// body 为原始请求体。
+//
+// 事件订阅的签名为 sha256(timestamp + nonce + encryptKey + body) 的十六进制编码，需要提供 encryptKey；
+// 消息卡片回调的签名为 sha1(timestamp + nonce + token + body) 的十六进制编码，需要提供 token (Verification Token)。
+// 任一签名匹配时返回 true，参数为空的签名方式不参与校验。函数不检查时间戳是否过期。
+func VerifyCallback(token, encryptKey string, timestamp, nonce, body string, signature string) bool {
+	if encryptKey != "" {
+		h := sha256.Sum256([]byte(timestamp + nonce + encryptKey + body))
+		if hmac.Equal([]byte(hex.EncodeToString(h[:])), []byte(signature)) {
+			return true
+		}
+	}
+	if token != "" {
+		h := sha1.Sum([]byte(timestamp + nonce + token + body))
+		if hmac.Equal([]byte(hex.EncodeToString(h[:])), []byte(signature)) {
+			return true
+		}
+	}
+	return false
+}
+
+// 函数解密开启加密后的事件内容，返回解密后的 json。
+// encrypted 为请求体中 encrypt 字段的值。
+// 解密方式为 AES-256-CBC，密钥为 encryptKey 的 sha256 值，密文的前 16 字节为 iv，填充方式为 PKCS#7。
+func DecryptEvent(encryptKey, encrypted string) ([]byte, error) {
+	bs, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("解密失败: %w", err)
+	}
+	if len(bs) < 2*aes.BlockSize || len(bs)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("解密失败: 密文长度错误: %d", len(bs))
+	}
+
+	key := sha256.Sum256([]byte(encryptKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("解密失败: %w", err)
+	}
+
+	iv, data := bs[:aes.BlockSize], bs[aes.BlockSize:]
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	return unpad(out)
+}
+
+// 函数去除 PKCS#7 填充。
+func unpad(bs []byte) ([]byte, error) {
+	n := int(bs[len(bs)-1])
+	if n < 1 || n > aes.BlockSize || n > len(bs) {
+		return nil, errors.New("解密失败: 填充错误")
+	}
+	for _, b := range bs[len(bs)-n:] {
+		if int(b) != n {
+			return nil, errors.New("解密失败: 填充错误")
+		}
+	}
+	return bs[:len(bs)-n], nil
+}
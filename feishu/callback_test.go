@@ -0,0 +1,114 @@
+package feishu
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kvii/bot/internal/errmatch"
+)
+
+func TestVerifyCallback(t *testing.T) {
+	const (
+		timestamp = "1599360473"
+		nonce     = "abc"
+		eventBody = `{"encrypt":"P37w+VZImNgPEO1RBhJ6RtKl7n6zymIbEG1pReEzghk="}`
+		cardBody  = `{"action":{}}`
+	)
+	testCases := []struct {
+		name       string // 测试项目
+		token      string // Verification Token
+		encryptKey string // Encrypt Key
+		body       string // 请求体
+		signature  string // 签名
+		expect     bool   // 预期结果
+	}{
+		{
+			name:       "event",
+			encryptKey: "test key",
+			body:       eventBody,
+			signature:  "32c781ccb61aa95c32dc501b3fceed292e2ba4bf929bbc8aaab2a693da8859cf",
+			expect:     true,
+		},
+		{
+			name:       "event wrong key",
+			encryptKey: "wrong key",
+			body:       eventBody,
+			signature:  "32c781ccb61aa95c32dc501b3fceed292e2ba4bf929bbc8aaab2a693da8859cf",
+			expect:     false,
+		},
+		{
+			name:      "card",
+			token:     "token",
+			body:      cardBody,
+			signature: "9296c45d1b18d34ce0792b4d52b487daa8961afb",
+			expect:    true,
+		},
+		{
+			name:      "card tampered body",
+			token:     "token",
+			body:      `{"action":{"value":1}}`,
+			signature: "9296c45d1b18d34ce0792b4d52b487daa8961afb",
+			expect:    false,
+		},
+		{
+			name:      "no key",
+			body:      eventBody,
+			signature: "",
+			expect:    false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := VerifyCallback(tc.token, tc.encryptKey, timestamp, nonce, tc.body, tc.signature)
+			if got != tc.expect {
+				t.Fatalf("expect %t, got %t", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestDecryptEvent(t *testing.T) {
+	testCases := []struct {
+		name      string // 测试项目
+		key       string // Encrypt Key
+		encrypted string // 密文
+		expect    string // 预期明文
+		err       error  // 预期错误
+	}{
+		{
+			name:      "official example",
+			key:       "test key",
+			encrypted: "P37w+VZImNgPEO1RBhJ6RtKl7n6zymIbEG1pReEzghk=",
+			expect:    "hello world",
+		},
+		{
+			name:      "wrong key",
+			key:       "wrong key",
+			encrypted: "P37w+VZImNgPEO1RBhJ6RtKl7n6zymIbEG1pReEzghk=",
+			err:       errmatch.Contains("解密失败"),
+		},
+		{
+			name:      "not base64",
+			key:       "test key",
+			encrypted: "!!!",
+			err:       errmatch.Contains("解密失败"),
+		},
+		{
+			name:      "too short",
+			key:       "test key",
+			encrypted: "AAAA",
+			err:       errmatch.Contains("解密失败"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecryptEvent(tc.key, tc.encrypted)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if string(got) != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package feishu
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// 跳转链接不是 https 地址时返回
+var ErrInsecureURL = errors.New("feishu: action url must be https")
+
+// 消息卡片
+type Card struct {
+	Config   *CardConfig   `json:"config,omitempty"`   // 卡片配置
+	Header   *CardHeader   `json:"header,omitempty"`   // 卡片标题
+	Elements []CardElement `json:"elements,omitempty"` // 卡片内容
+}
+
+// 卡片配置
+type CardConfig struct {
+	WideScreenMode bool `json:"wide_screen_mode"`         // 是否根据屏幕宽度动态调整卡片宽度
+	EnableForward  bool `json:"enable_forward,omitempty"` // 是否允许转发卡片
+}
+
+// 卡片标题
+type CardHeader struct {
+	Title    CardText `json:"title"`              // 标题文本
+	Template string   `json:"template,omitempty"` // 标题主题颜色，如 blue、red
+}
+
+// 卡片文本标签
+type CardTextTag = string
+
+const (
+	CardTextTagPlainText CardTextTag = "plain_text" // 普通文本
+	CardTextTagLarkMd    CardTextTag = "lark_md"    // 支持部分 markdown 语法的文本
+)
+
+// 卡片文本
+type CardText struct {
+	Tag     CardTextTag `json:"tag"`     // 文本标签
+	Content string      `json:"content"` // 文本内容
+}
+
+// 卡片元素标签
+type CardTag = string
+
+const (
+	CardTagDiv      CardTag = "div"      // 内容模块
+	CardTagMarkdown CardTag = "markdown" // markdown 模块
+	CardTagHr       CardTag = "hr"       // 分割线模块
+	CardTagAction   CardTag = "action"   // 交互模块
+	CardTagButton   CardTag = "button"   // 按钮。只能用于交互模块中。
+)
+
+// 卡片元素
+type CardElement struct {
+	Tag     CardTag       `json:"tag"`               // 元素标签
+	Text    *CardText     `json:"text,omitempty"`    // 文本。div 和 button 使用。
+	Fields  []CardField   `json:"fields,omitempty"`  // 字段列表。div 使用。
+	Content string        `json:"content,omitempty"` // markdown 内容。markdown 使用。
+	Actions []CardElement `json:"actions,omitempty"` // 交互元素列表。action 使用。
+	URL     string        `json:"url,omitempty"`     // 跳转链接。button 使用。
+	Type    string        `json:"type,omitempty"`    // 按钮类型，如 default、primary、danger。button 使用。
+}
+
+// 卡片字段
+type CardField struct {
+	IsShort bool     `json:"is_short"` // 是否并排布局
+	Text    CardText `json:"text"`     // 字段文本
+}
+
+// 卡片模板
+type CardTemplate struct {
+	Type string           `json:"type"` // 固定为 template
+	Data CardTemplateData `json:"data"` // 模板数据
+}
+
+// 卡片模板数据
+type CardTemplateData struct {
+	TemplateID       string         `json:"template_id"`                 // 卡片模板 ID
+	TemplateVariable map[string]any `json:"template_variable,omitempty"` // 卡片模板变量
+}
+
+// 方法构造卡片按钮的跳转链接。
+// 链接必须为 https 地址，否则返回 [ErrInsecureURL]。params 会合并到链接的查询参数中。
+// 设置 Secret 时，附加 timestamp 与 sign 参数，时间戳取自客户端的时间来源。
+// 签名覆盖链接的路径与全部查询参数 (包括 timestamp)，修改任一部分都会导致签名失效，
+// 接收方可以用同一密钥通过 [VerifyCardActionURL] 校验链接来源。
+func (c BotClient) CardActionURL(rawURL string, params url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("跳转链接错误: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("%w: %q", ErrInsecureURL, rawURL)
+	}
+
+	q := u.Query()
+	for k, vs := range params {
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+	if c.Secret != "" {
+		q.Set("timestamp", strconv.FormatInt(c.now().Unix(), 10))
+		q.Del("sign")
+		q.Set("sign", signAction(c.Secret, u.EscapedPath(), q.Encode()))
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// 函数校验 [BotClient.CardActionURL] 生成的跳转链接的签名。
+// secret 为生成链接的客户端的 Secret。链接的路径或查询参数被修改、缺少签名时返回 false。
+// 函数不检查时间戳是否过期，调用方可以通过 timestamp 参数自行判断。
+func VerifyCardActionURL(rawURL string, secret string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	q := u.Query()
+	signature := q.Get("sign")
+	if signature == "" || q.Get("timestamp") == "" {
+		return false
+	}
+	q.Del("sign")
+	want := signAction(secret, u.EscapedPath(), q.Encode())
+	return hmac.Equal([]byte(want), []byte(signature))
+}
+
+// 函数计算跳转链接的签名。
+// 签名内容为 "路径?按键排序的查询参数"，算法为以 secret 为密钥的 HmacSHA256，结果进行 Base64 编码。
+func signAction(secret, path, query string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(path + "?" + query))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,94 @@
+package feishu
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCardActionURL(t *testing.T) {
+	testCases := []struct {
+		name   string     // 测试项目
+		rawURL string     // 跳转链接
+		params url.Values // 查询参数
+		want   string     // 预期链接
+		err    error      // 预期错误
+	}{
+		{
+			name:   "normal",
+			rawURL: "https://example.com/approve?id=1",
+			params: url.Values{"user": {"kvii"}},
+			want:   "https://example.com/approve?id=1&user=kvii",
+			err:    nil,
+		},
+		{
+			name:   "http",
+			rawURL: "http://example.com/approve",
+			err:    ErrInsecureURL,
+		},
+		{
+			name:   "javascript",
+			rawURL: "javascript:alert(1)",
+			err:    ErrInsecureURL,
+		},
+		{
+			name:   "relative",
+			rawURL: "/approve",
+			err:    ErrInsecureURL,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := BotClient{}.CardActionURL(tc.rawURL, tc.params)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if got != tc.want {
+				t.Fatalf("expect %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCardActionURL_Sign(t *testing.T) {
+	c := BotClient{
+		Secret: "secret",
+		clock:  clock{now: func() time.Time { return time.Unix(1599360473, 0) }},
+	}
+	got, err := c.CardActionURL("https://example.com/approve", url.Values{"id": {"1"}})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if ts := u.Query().Get("timestamp"); ts != "1599360473" {
+		t.Fatalf("expect timestamp %q, got %q", "1599360473", ts)
+	}
+	if !VerifyCardActionURL(got, "secret") {
+		t.Fatalf("expect valid sign: %s", got)
+	}
+
+	testCases := []struct {
+		name   string // 测试项目
+		rawURL string // 跳转链接
+		secret string // 签名密钥
+	}{
+		{name: "wrong secret", rawURL: got, secret: "wrong"},
+		{name: "modified param", rawURL: strings.Replace(got, "id=1", "id=2", 1), secret: "secret"},
+		{name: "added param", rawURL: got + "&admin=1", secret: "secret"},
+		{name: "modified path", rawURL: strings.Replace(got, "/approve", "/reject", 1), secret: "secret"},
+		{name: "modified timestamp", rawURL: strings.Replace(got, "1599360473", "1599360474", 1), secret: "secret"},
+		{name: "no sign", rawURL: "https://example.com/approve?id=1&timestamp=1599360473", secret: "secret"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if VerifyCardActionURL(tc.rawURL, tc.secret) {
+				t.Fatalf("expect invalid sign: %s", tc.rawURL)
+			}
+		})
+	}
+}
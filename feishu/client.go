@@ -3,7 +3,11 @@ package feishu
 import (
 	"bytes"
 	"cmp"
+	"compress/gzip"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,20 +15,37 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/kvii/bot/internal/backoff"
 )
 
 // 信息类型
 type MessageType = string
 
 const (
-	MessageTypeText MessageType = "text" // 文本信息类型
+	MessageTypeText        MessageType = "text"        // 文本信息类型
+	MessageTypePost        MessageType = "post"        // 富文本信息类型
+	MessageTypeImage       MessageType = "image"       // 图片信息类型
+	MessageTypeInteractive MessageType = "interactive" // 消息卡片类型
+	MessageTypeShareChat   MessageType = "share_chat"  // 分享群名片信息类型
+	MessageTypeShareUser   MessageType = "share_user"  // 分享个人名片信息类型
+	MessageTypeFile        MessageType = "file"        // 文件信息类型
+	MessageTypeAudio       MessageType = "audio"       // 语音信息类型
+	MessageTypeMedia       MessageType = "media"       // 视频信息类型
 )
 
 // 信息
 type Message struct {
-	MsgType MessageType `json:"msg_type"` // 信息类型
-	Content any         `json:"content"`  // 信息内容
+	Timestamp string      `json:"timestamp,omitempty"` // 时间戳，单位为秒。开启签名校验时由客户端填写。
+	Sign      string      `json:"sign,omitempty"`      // 签名。开启签名校验时由客户端填写。
+	MsgType   MessageType `json:"msg_type"`            // 信息类型
+	Content   any         `json:"content,omitempty"`   // 信息内容
+	Card      any         `json:"card,omitempty"`      // 消息卡片。消息卡片类型使用。
 }
 
 // 文本信息
@@ -32,107 +53,1036 @@ type TextMessage struct {
 	Text string `json:"text"` // 文本内容
 }
 
+// 图片信息
+type ImageMessage struct {
+	ImageKey string `json:"image_key"` // 图片 key，通过上传图片接口获取
+}
+
+// 文件或语音信息
+type FileMessage struct {
+	FileKey string `json:"file_key"` // 文件 key，通过上传文件接口获取
+}
+
+// 视频信息
+type MediaMessage struct {
+	FileKey  string `json:"file_key"`            // 视频文件 key，通过上传文件接口获取
+	ImageKey string `json:"image_key,omitempty"` // 视频封面图片 key，通过上传图片接口获取
+}
+
+// 分享群名片信息
+type ShareChatMessage struct {
+	ShareChatID string `json:"share_chat_id"` // 群 ID
+}
+
+// 分享个人名片信息
+type ShareUserMessage struct {
+	UserID string `json:"user_id"` // 用户 open_id
+}
+
+// 请求体大小上限，单位为字节。
+// 自定义机器人的请求体不能超过 20KB，调用方可以据此预先拆分内容。
+const MaxRequestBytes = 20 << 10
+
+// 富文本信息
+type PostMessage struct {
+	Post map[string]PostContent `json:"post"` // 各语言的富文本内容，键为语言，如 zh_cn
+}
+
+// 富文本语言
+type Locale = string
+
+const (
+	LocaleZhCN Locale = "zh_cn" // 中文
+	LocaleEnUS Locale = "en_us" // 英文
+	LocaleJaJP Locale = "ja_jp" // 日文
+)
+
+// 富文本内容
+type PostContent struct {
+	Title   string          `json:"title"`   // 标题
+	Content [][]PostElement `json:"content"` // 内容。每个元素为一个段落。
+}
+
+// 富文本标签
+type PostTag = string
+
+const (
+	PostTagText PostTag = "text" // 文本标签
+	PostTagLink PostTag = "a"    // 超链接标签
+	PostTagAt   PostTag = "at"   // @ 标签
+)
+
+// 富文本元素
+type PostElement struct {
+	Tag      PostTag `json:"tag"`                 // 标签
+	Text     string  `json:"text,omitempty"`      // 文本内容。text 和 a 标签使用。
+	Href     string  `json:"href,omitempty"`      // 链接地址。a 标签使用。
+	UserID   string  `json:"user_id,omitempty"`   // 用户 open_id，all 表示所有人。at 标签使用。
+	UserName string  `json:"user_name,omitempty"` // 用户名称。at 标签使用。
+}
+
 // 发送响应
 type SendResponse[T any] struct {
 	Code int    `json:"code"` // 响应码。非 0 为异常。
 	Data T      `json:"data"` // 返回数据
 	Msg  string `json:"msg"`  // 异常信息
+
+	// 旧版接口的响应码与异常信息。部分响应只在此处返回异常，code 仍为 0。
+	StatusCode    int    `json:"StatusCode"`
+	StatusMessage string `json:"StatusMessage"`
+}
+
+// 接口错误。code 非 0 时返回。
+// 可以通过 errors.As 获取错误码。
+type APIError struct {
+	Code int    // 响应码
+	Msg  string // 异常信息
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("响应异常: %d %s", e.Code, e.Msg)
+}
+
+// 响应状态错误。响应状态码不为 200 时返回。
+// 可以通过 errors.As 获取响应头，如读取 Retry-After。
+// 开启 PreserveResponseBody 时，收到响应后发生的其他错误也会包装为该类型，此时 Err 为原错误。
+type HTTPError struct {
+	StatusCode int         // 响应状态码
+	Header     http.Header // 响应头
+	Body       []byte      // 响应内容
+	Err        error       // 原错误。仅在开启 PreserveResponseBody 时设置
+}
+
+func (e HTTPError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("响应状态错误: %d", e.StatusCode)
+}
+
+func (e HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// 常见响应码
+const (
+	CodeBadRequest   = 9499  // 请求错误
+	CodeParamInvalid = 19001 // 参数错误
+	CodeSignMismatch = 19021 // 签名校验失败
+	CodeFreqLimited  = 11232 // 发送频率超过限制
+)
+
+// 函数判断错误是否由限流引起。
+func isRateLimited(err error) bool {
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == CodeFreqLimited
+	}
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	return false
 }
 
 // 预定义错误
 var (
-	ErrNeedToken = errors.New("feishu: need token") // 需要提供令牌
+	ErrNeedToken              = errors.New("feishu: need token")                                        // 需要提供令牌
+	ErrProxyConflict          = errors.New("feishu: ProxyURL conflicts with Client, Transport or Doer") // 代理配置冲突
+	ErrResponseTooLarge       = errors.New("feishu: response too large")                                // 响应内容过大
+	ErrUnexpectedContentType  = errors.New("feishu: unexpected content type")                           // 响应类型错误
+	ErrNeedTenantToken        = errors.New("feishu: need tenant access token")                          // 需要提供应用令牌
+	ErrImageTooLarge          = errors.New("feishu: image too large")                                   // 图片过大
+	ErrEmptyID                = errors.New("feishu: empty id")                                          // 需要提供 ID
+	ErrUnsupportedMessageType = errors.New("feishu: unsupported message type")                          // 不支持的信息类型
 )
 
+// 重试配置
+type RetryConfig struct {
+	MaxAttempts int           // 最大尝试次数。不大于 1 时不重试。
+	BaseDelay   time.Duration // 首次重试前的等待时间，之后每次翻倍，单次最长 5 分钟。
+}
+
+// 返回丢弃所有输出的 logger。
+// 将 BotClient 的 Logger 设置为该函数的返回值即可关闭所有日志。
+func NopLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// 返回适合突发流量的 http client。
+// 默认的 [http.DefaultClient] 每个主机只保留 2 个空闲连接，高并发发送时会频繁新建连接并耗尽临时端口。
+// 在多个 goroutine 间共享同一个 BotClient 时，建议将其 Client 设置为该函数的返回值。
+func DefaultPooledClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 20
+	t.IdleConnTimeout = 90 * time.Second
+	return &http.Client{Transport: t}
+}
+
 // 飞书机器人客户端
 type BotClient struct {
-	Client  *http.Client // 底层 http client。不填则使用默认值。
-	Logger  *slog.Logger // 日志 logger。不填则使用默认值。
-	BaseURL string       // 飞书接口基础地址。不填则使用默认值。
-	Token   string       // 机器人令牌。
+	Client      *http.Client // 底层 http client。不填则使用默认值。
+	Logger      *slog.Logger // 日志 logger。不填则使用默认值，设置为 NopLogger() 时不输出日志。
+	BaseURL     string       // 飞书接口基础地址。不填则使用默认值。
+	HookVersion string       // webhook 接口版本。不填则使用默认值 v2。
+	UserAgent   string       // 请求的 User-Agent。不填则使用默认值 kvii-bot/1.0。
+	Token       string       // 机器人令牌。ctx 中通过 ContextWithToken 保存的令牌优先。
+	Secret      string       // 签名密钥。不填则不进行签名。
+	Retry       RetryConfig  // 限流时的重试配置。不填则不重试。
+
+	// 客户端共享的重试预算。设置后每次重试消耗预算，预算耗尽时不再重试，
+	// 返回的错误包含 [ErrRetryBudgetExhausted]，可以在 OnSend 中据此统计。不填则不限制。
+	RetryBudget *RetryBudget
+
+	TenantAccessToken string // 应用的 tenant_access_token。仅上传图片时使用，webhook 机器人本身不需要。
+
+	// 执行请求的 Doer，设置后优先于 Client 与 Transport 使用。
+	// 可用于在不启动 http 服务的情况下模拟响应，或接入自定义的重试逻辑。
+	Doer Doer
+
+	// 底层 http 传输层。仅在 Doer 与 Client 都为空时使用，用于在不接管 client 构造的情况下
+	// 注入链路追踪、指标统计或自定义请求头等中间件。
+	Transport http.RoundTripper
+
+	// 附加的请求头，如 API 网关的鉴权令牌。发送时会复制到每个请求上。
+	// Content-Type 由客户端决定，不能被覆盖；User-Agent 请使用 UserAgent 字段设置。
+	Headers http.Header
+
+	// 请求的 Accept-Language，如 en-US。飞书会按该语言返回部分异常信息。
+	// 不填则不设置，此时 Headers 中的 Accept-Language 仍然有效。
+	AcceptLanguage string
+
+	// 是否压缩较大的请求体。开启后，超过 1KB 的请求体会以 gzip 压缩发送，并设置 Content-Encoding: gzip。
+	// 注意：官方文档没有说明接口支持压缩请求。服务端以 415 拒绝压缩请求时，会自动改为不压缩重试一次。
+	Compress bool
+
+	// 代理地址，如 http://proxy.example.com:8080。仅在 Doer、Client 与 Transport 都为空时使用。
+	// 与 Doer、Client 或 Transport 同时设置时，发送时返回 [ErrProxyConflict]。
+	ProxyURL string
+
+	// 是否以 Debug 级别记录请求地址、请求内容与响应内容。
+	// 记录的请求地址中的令牌会被替换为 ***。
+	LogBody bool
+
+	// 发送记录。不填则不记录，此时 LastSentAt 总是返回零值。
+	// 需要读取最近一次发送时间时，设置为 new(SendTracker)。
+	Tracker *SendTracker
+
+	// 发送前修改信息的钩子，在校验之后、序列化之前调用，可用于统一追加页脚或链路追踪标识等。
+	// 返回错误时中止发送并返回该错误。修改后的信息不会再次校验。
+	// 可以修改 MsgType，但不建议这样做。不填则不调用。
+	BeforeSend func(ctx context.Context, msg *Message) error
+
+	// 发送结束时的回调，成功与失败时都会调用，可用于接入指标统计。
+	// duration 为最后一次请求从发送前到响应解析完成的耗时，不包含限流等待与重试间隔，未发送请求时为 0。
+	OnSend func(msgType string, duration time.Duration, err error)
+
+	// 发送失败时的回调，参数为发送失败的信息与错误，可用于将信息写入死信队列后重新发送。
+	// 与 OnSend 不同，仅在失败时调用，并提供完整的信息；信息经过 BeforeSend 修改时为修改后的信息。
+	// SendJSON 发送的请求体不是 Message，失败时不会调用。不填则不调用。
+	OnError func(ctx context.Context, msg Message, err error)
+
+	// 试运行。开启后执行参数检查与序列化，并以 Info 级别记录请求内容，但不发送请求。
+	// 参数检查失败时仍然返回错误。
+	DryRun bool
+
+	// 从 ctx 中提取日志属性，如链路追踪 id。不填则不提取。
+	// 设置后，每条日志都会附加该函数返回的属性。
+	LogAttrsFromContext func(ctx context.Context) []slog.Attr
+
+	// json 序列化与反序列化函数，可替换为更快的实现。不填则使用 encoding/json。
+	Marshal   func(v any) ([]byte, error)
+	Unmarshal func(data []byte, v any) error
+
+	// 自定义的响应检查函数，参数为响应状态码与响应内容，返回错误表示发送失败。
+	// 设置后替代默认的状态码、响应类型与响应码检查，适用于会改写响应格式的网关。
+	// 返回 [APIError] 或 [HTTPError] 时，限流重试等依赖错误类型的逻辑仍然有效。
+	// 响应内容能解析为 json 时，仍会解析到返回的响应中。不填则使用默认检查。
+	SuccessFunc func(status int, body []byte) error
+
+	// 是否在发送失败时保留完整的响应内容。开启后，响应码错误、响应类型错误等收到响应后发生的错误
+	// 都会包装为 [HTTPError]，可以通过 errors.As 读取响应内容，原错误仍可通过 errors.Is 与 errors.As 匹配。
+	// 响应内容可能较大，因此默认不开启。响应状态码不为 200 时总会返回带有响应内容的 [HTTPError]。
+	PreserveResponseBody bool
+
+	// 是否转义文本信息中的 &、< 与 >。不开启时 (默认) 文本内容原样发送，客户端不做任何转义，
+	// 内容中的 <at> 等标签会被飞书解析；开启后这些字符会转义为 &amp;、&lt; 与 &gt;，按字面显示。
+	// 仅对 SendText、SendTextf、SendTextWithMentions 与 IMClient.SendText 的文本内容生效，
+	// SendTextWithMentions 生成的 @ 标签不会被转义。
+	EscapeText bool
+
+	// 响应内容的大小上限，单位为字节。不填则使用默认值 1MB。
+	// 用于避免异常的代理或网关返回过大的响应耗尽内存，超过时返回 [ErrResponseTooLarge]。
+	MaxResponseBytes int64
+
+	// 默认超时时间。ctx 没有截止时间时使用，不填则不设置超时。
+	// ctx 自带的截止时间总是优先。
+	Timeout time.Duration
+
+	clock clock // 时间来源，仅用于测试
 }
 
 // 方法发送文本信息。
 // 目前只支持文本信息。信息内容需要包含指定关键字。
+// 内容默认原样发送，开启 EscapeText 时转义其中的特殊字符。
 func (c BotClient) SendText(ctx context.Context, msg string) error {
-	c.logger().InfoContext(ctx, "发送文本消息", slog.String("msg", msg))
+	c.logger().InfoContext(ctx, "发送文本消息")
 
 	return c.send(ctx, Message{
 		MsgType: MessageTypeText,
-		Content: TextMessage{Text: msg},
+		Content: TextMessage{Text: c.escapeText(msg)},
+	})
+}
+
+// 文本信息中特殊字符的转义
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// 方法在开启 EscapeText 时转义文本内容中的特殊字符，否则原样返回。
+func (c BotClient) escapeText(msg string) string {
+	if !c.EscapeText {
+		return msg
+	}
+	return textEscaper.Replace(msg)
+}
+
+// 方法按 format 格式化后发送文本信息，见 [BotClient.SendText]。
+func (c BotClient) SendTextf(ctx context.Context, format string, args ...any) error {
+	return c.SendText(ctx, fmt.Sprintf(format, args...))
+}
+
+// 方法最多等待 d 时长发送文本信息，见 [BotClient.SendText]。
+// 超时后返回 context.DeadlineExceeded。ctx 的截止时间更早时以 ctx 为准。
+func (c BotClient) SendTextTimeout(ctx context.Context, d time.Duration, msg string) error {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	return c.SendText(ctx, msg)
+}
+
+// 发送富文本信息。
+// content 中每个元素为一个段落，信息内容使用中文 (zh_cn)。
+func (c BotClient) SendPost(ctx context.Context, title string, content [][]PostElement) error {
+	c.logger().InfoContext(ctx, "发送富文本消息")
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypePost,
+		Content: PostMessage{Post: map[string]PostContent{
+			LocaleZhCN: {Title: title, Content: content},
+		}},
+	})
+}
+
+// 发送多语言富文本信息，客户端会按用户的语言设置显示对应内容。
+// posts 的键为语言，需要为 [LocaleZhCN]、[LocaleEnUS] 或 [LocaleJaJP]，且至少包含一种语言。
+func (c BotClient) SendPostMultiLang(ctx context.Context, posts map[Locale]PostContent) error {
+	c.logger().InfoContext(ctx, "发送富文本消息", slog.Int("locales", len(posts)))
+
+	if len(posts) == 0 {
+		c.logger().ErrorContext(ctx, "需要提供富文本内容")
+		return errors.New("富文本内容为空: 至少需要一种语言")
+	}
+	for locale := range posts {
+		switch locale {
+		case LocaleZhCN, LocaleEnUS, LocaleJaJP:
+		default:
+			c.logger().ErrorContext(ctx, "富文本语言错误", slog.String("locale", locale))
+			return fmt.Errorf("富文本语言错误: %q, 需要为 %s、%s 或 %s", locale, LocaleZhCN, LocaleEnUS, LocaleJaJP)
+		}
+	}
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypePost,
+		Content: PostMessage{Post: posts},
+	})
+}
+
+// 发送图片信息。
+// imageKey 需要通过飞书开放平台的上传图片接口获取，见 [BotClient.UploadImage]。
+// 自定义机器人的 webhook 本身无法上传图片，只能发送已上传图片的 image_key。
+func (c BotClient) SendImage(ctx context.Context, imageKey string) error {
+	c.logger().InfoContext(ctx, "发送图片消息", slog.String("imageKey", imageKey))
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeImage,
+		Content: ImageMessage{ImageKey: imageKey},
+	})
+}
+
+// 发送群名片信息。chatID 为群 ID，如 oc_xxx。
+func (c BotClient) SendShareChat(ctx context.Context, chatID string) error {
+	c.logger().InfoContext(ctx, "发送群名片消息", slog.String("chatID", chatID))
+
+	if chatID == "" {
+		c.logger().ErrorContext(ctx, "需要提供群 ID")
+		return ErrEmptyID
+	}
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeShareChat,
+		Content: ShareChatMessage{ShareChatID: chatID},
+	})
+}
+
+// 发送个人名片信息。userID 为用户的 open_id，如 ou_xxx。
+func (c BotClient) SendShareUser(ctx context.Context, userID string) error {
+	c.logger().InfoContext(ctx, "发送个人名片消息", slog.String("userID", userID))
+
+	if userID == "" {
+		c.logger().ErrorContext(ctx, "需要提供用户 ID")
+		return ErrEmptyID
+	}
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeShareUser,
+		Content: ShareUserMessage{UserID: userID},
+	})
+}
+
+// 发送文件信息。
+// fileKey 需要通过飞书开放平台的上传文件接口获取，该接口不属于 webhook 机器人的能力范围。
+func (c BotClient) SendFile(ctx context.Context, fileKey string) error {
+	c.logger().InfoContext(ctx, "发送文件消息", slog.String("fileKey", fileKey))
+
+	if fileKey == "" {
+		c.logger().ErrorContext(ctx, "需要提供文件 key")
+		return ErrEmptyID
+	}
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeFile,
+		Content: FileMessage{FileKey: fileKey},
+	})
+}
+
+// 发送语音信息。
+// fileKey 需要通过飞书开放平台的上传文件接口以 opus 格式上传获取，该接口不属于 webhook 机器人的能力范围。
+func (c BotClient) SendAudio(ctx context.Context, fileKey string) error {
+	c.logger().InfoContext(ctx, "发送语音消息", slog.String("fileKey", fileKey))
+
+	if fileKey == "" {
+		c.logger().ErrorContext(ctx, "需要提供文件 key")
+		return ErrEmptyID
+	}
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeAudio,
+		Content: FileMessage{FileKey: fileKey},
+	})
+}
+
+// 发送视频信息。
+// fileKey 需要通过飞书开放平台的上传文件接口以 mp4 格式上传获取，imageKey 为视频封面，
+// 通过上传图片接口获取，见 [BotClient.UploadImage]。两者都不属于 webhook 机器人的能力范围。
+func (c BotClient) SendMedia(ctx context.Context, fileKey, imageKey string) error {
+	c.logger().InfoContext(ctx, "发送视频消息", slog.String("fileKey", fileKey), slog.String("imageKey", imageKey))
+
+	if fileKey == "" || imageKey == "" {
+		c.logger().ErrorContext(ctx, "需要提供文件 key 与封面图片 key")
+		return ErrEmptyID
+	}
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeMedia,
+		Content: MediaMessage{FileKey: fileKey, ImageKey: imageKey},
+	})
+}
+
+// 发送消息卡片。
+// card 可以使用 [Card] 构建，也可以使用卡片搭建工具生成的 json 对象。
+func (c BotClient) SendCard(ctx context.Context, card any) error {
+	c.logger().InfoContext(ctx, "发送消息卡片")
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeInteractive,
+		Card:    card,
+	})
+}
+
+// 使用卡片模板发送消息卡片。
+// templateID 为卡片搭建工具中发布的模板 ID，vars 为模板变量。
+func (c BotClient) SendCardTemplate(ctx context.Context, templateID string, vars map[string]any) error {
+	c.logger().InfoContext(ctx, "发送模板消息卡片", slog.String("templateID", templateID))
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeInteractive,
+		Card: CardTemplate{
+			Type: "template",
+			Data: CardTemplateData{TemplateID: templateID, TemplateVariable: vars},
+		},
+	})
+}
+
+// 发送消息卡片，并返回解析后的响应。
+func (c BotClient) SendCardRaw(ctx context.Context, card any) (SendResponse[map[string]any], error) {
+	c.logger().InfoContext(ctx, "发送消息卡片")
+
+	return sendTyped[map[string]any](ctx, c, Message{
+		MsgType: MessageTypeInteractive,
+		Card:    card,
 	})
 }
 
 // 方法发送信息。
-// 目前只支持文本信息。信息内容需要包含指定关键字。
+// 信息类型不是已知类型时返回 [ErrUnsupportedMessageType]，新增的类型可以使用 [BotClient.SendJSON] 发送。
 func (c BotClient) Send(ctx context.Context, msg Message) error {
 	c.logger().InfoContext(ctx, "发送消息", slog.String("msgType", msg.MsgType))
 	return c.send(ctx, msg)
 }
 
+// 方法按顺序依次发送多条信息，适用于需要保证顺序的多段公告。
+// 遇到错误时立即停止，返回的错误中包含失败信息的序号 (从 1 开始)，之后的信息不会发送。
+// 每条信息发送前检查 ctx，ctx 结束时返回 ctx.Err()。
+func (c BotClient) SendAll(ctx context.Context, msgs []Message) error {
+	c.logger().InfoContext(ctx, "依次发送消息", slog.Int("count", len(msgs)))
+
+	for i, msg := range msgs {
+		if err := ctx.Err(); err != nil {
+			c.logger().ErrorContext(ctx, "发送中止", slog.Int("index", i), slog.Any("err", err))
+			return fmt.Errorf("第 %d 条信息发送前中止 (共 %d 条): %w", i+1, len(msgs), err)
+		}
+		if err := c.send(ctx, msg); err != nil {
+			c.logger().ErrorContext(ctx, "依次发送失败", slog.Int("index", i), slog.Any("err", err))
+			return fmt.Errorf("第 %d 条信息发送失败 (共 %d 条): %w", i+1, len(msgs), err)
+		}
+	}
+	return nil
+}
+
+// 方法发送信息，并返回解析后的响应。
+// 响应异常时同时返回响应与错误，便于调用方读取响应数据。
+func (c BotClient) SendRaw(ctx context.Context, msg Message) (SendResponse[map[string]any], error) {
+	c.logger().InfoContext(ctx, "发送消息", slog.String("msgType", msg.MsgType))
+	return sendTyped[map[string]any](ctx, c, msg)
+}
+
+// 函数使用客户端 c 发送信息，并将响应数据解析为 T 类型。
+// 由于方法不能带类型参数，该函数以包级函数的形式提供。
+// 响应异常时同时返回响应与错误，便于调用方读取响应数据。
+func SendTyped[T any](ctx context.Context, c BotClient, msg Message) (SendResponse[T], error) {
+	c.logger().InfoContext(ctx, "发送消息", slog.String("msgType", msg.MsgType))
+	return sendTyped[T](ctx, c, msg)
+}
+
 func (c BotClient) send(ctx context.Context, msg Message) error {
+	_, err := sendTyped[struct{}](ctx, c, msg)
+	return err
+}
+
+// 函数发送信息，并将响应数据解析为 T 类型。
+func sendTyped[T any](ctx context.Context, c BotClient, msg Message) (SendResponse[T], error) {
+	data, err := sendPayload[T](ctx, c, msg.MsgType, func(timestamp, signature string) ([]byte, error) {
+		if !isSupported(msg.MsgType) {
+			c.logger().ErrorContext(ctx, "不支持的信息类型", slog.String("msgType", msg.MsgType))
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedMessageType, msg.MsgType)
+		}
+		if c.BeforeSend != nil {
+			if err := c.BeforeSend(ctx, &msg); err != nil {
+				c.logger().ErrorContext(ctx, "发送前钩子返回错误", slog.Any("err", err))
+				return nil, err
+			}
+		}
+		msg.Timestamp, msg.Sign = timestamp, signature
+		bs, err := c.marshal(msg)
+		if err != nil {
+			c.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
+			return nil, err
+		}
+		return bs, nil
+	})
+	if err != nil && c.OnError != nil {
+		c.OnError(ctx, msg, err)
+	}
+	return data, err
+}
+
+// 方法发送调用方提供的 json 请求体，适用于本包尚未支持的信息类型。
+// 该方法不检查信息类型，可以发送飞书新增的信息类型。请求体需要为合法的 json 对象，且 msg_type 为非空字符串。
+// 未设置 Secret 时原样发送；
+// 设置 Secret 时会在其中加入 timestamp 与 sign 字段后重新序列化。
+// 地址、签名、重试与响应处理与其他方法相同。
+func (c BotClient) SendJSON(ctx context.Context, raw json.RawMessage) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		c.logger().ErrorContext(ctx, "json 格式错误", slog.Any("err", err))
+		return errors.New("json 格式错误: 需要为 json 对象")
+	}
+	var msgType MessageType
+	if err := json.Unmarshal(fields["msg_type"], &msgType); err != nil || msgType == "" {
+		c.logger().ErrorContext(ctx, "信息类型错误", slog.Any("err", err))
+		return errors.New("json 格式错误: msg_type 需要为非空字符串")
+	}
+	c.logger().InfoContext(ctx, "发送 json 消息", slog.String("msgType", msgType))
+
+	_, err := sendPayload[struct{}](ctx, c, msgType, func(timestamp, signature string) ([]byte, error) {
+		if timestamp == "" {
+			return raw, nil
+		}
+		fields["timestamp"], _ = json.Marshal(timestamp)
+		fields["sign"], _ = json.Marshal(signature)
+		return json.Marshal(fields)
+	})
+	return err
+}
+
+// 函数发送 encode 生成的请求体，并将响应数据解析为 T 类型。
+// 设置 Secret 时，encode 的参数为时间戳与签名，否则均为空字符串。
+func sendPayload[T any](ctx context.Context, c BotClient, msgType MessageType, encode func(timestamp, signature string) ([]byte, error)) (_ SendResponse[T], err error) {
+	c = c.withContextToken(ctx)
+
+	var elapsed time.Duration // 最后一次请求的耗时
+	if c.OnSend != nil {
+		defer func() {
+			c.OnSend(msgType, elapsed, err)
+		}()
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	if c.Token == "" {
 		c.logger().ErrorContext(ctx, "需要提供令牌")
-		return ErrNeedToken
+		return SendResponse[T]{}, ErrNeedToken
 	}
 
-	u, err := url.Parse(c.baseURL())
+	u, err := c.webhookURL()
 	if err != nil {
 		c.logger().ErrorContext(ctx, "URL 解析失败", slog.Any("err", err))
-		return err
+		return SendResponse[T]{}, err
+	}
+
+	var timestamp, signature string
+	if c.Secret != "" {
+		timestamp = strconv.FormatInt(c.signTime(ctx).Unix(), 10)
+		signature = sign(timestamp, c.Secret)
 	}
-	u = u.JoinPath("/open-apis/bot/v2/hook/", c.Token)
 
-	bs, err := json.Marshal(msg)
+	bs, err := encode(timestamp, signature)
 	if err != nil {
-		c.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
-		return err
+		return SendResponse[T]{}, err
+	}
+
+	c.debugBody(ctx, "请求内容", slog.String("url", c.redact(u)), slog.String("body", string(bs)))
+
+	if c.DryRun {
+		c.logger().InfoContext(ctx, "试运行，跳过发送", slog.String("url", c.redact(u)), slog.String("body", string(bs)))
+		return SendResponse[T]{}, nil
+	}
+
+	var data SendResponse[T]
+	for attempt := 1; ; attempt++ {
+		reqStart := time.Now()
+		data, err = postTyped[T](ctx, c, u, bs, c.Compress && len(bs) > compressThreshold)
+		elapsed = time.Since(reqStart)
+		if err == nil {
+			break
+		}
+		if attempt >= c.Retry.MaxAttempts || !isRateLimited(err) {
+			return data, err
+		}
+		if !c.RetryBudget.Allow() {
+			c.logger().WarnContext(ctx, "重试预算耗尽，放弃重试", slog.Int("attempt", attempt))
+			return data, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+
+		delay := backoff.Delay(c.Retry.BaseDelay, attempt)
+		c.logger().WarnContext(ctx, "触发限流，等待重试", slog.Int("attempt", attempt), slog.Duration("delay", delay))
+
+		if err = c.sleep(ctx, delay); err != nil {
+			return data, err
+		}
+	}
+
+	c.Tracker.Record(c.now())
+	c.logger().InfoContext(ctx, "消息发送成功")
+	return data, nil
+}
+
+// 方法返回发送信息时请求的地址，其中的令牌会被替换为 ***。
+// 与发送时使用相同的地址构造逻辑，可用于排查问题。未设置 Token 时返回 [ErrNeedToken]。
+func (c BotClient) RequestURL() (string, error) {
+	if c.Token == "" {
+		return "", ErrNeedToken
+	}
+	u, err := c.webhookURL()
+	if err != nil {
+		return "", err
 	}
+	return c.redact(u), nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewBuffer(bs))
+// 方法构造 webhook 地址。
+func (c BotClient) webhookURL() (string, error) {
+	return buildURL(c.BaseURL, c.HookVersion, c.Token)
+}
+
+// 函数构造 webhook 地址。base 与 version 为空时使用默认值，token 作为路径的最后一段并进行转义。
+// base 中已有的路径与查询参数会被保留。
+func buildURL(base, version, token string) (string, error) {
+	u, err := url.Parse(cmp.Or(base, defaultBaseURL))
+	if err != nil {
+		return "", err
+	}
+	u = u.JoinPath("/open-apis/bot", cmp.Or(version, defaultHookVersion), "hook", token)
+	return u.String(), nil
+}
+
+// 函数判断信息类型是否为 webhook 机器人支持的类型。
+func isSupported(msgType MessageType) bool {
+	switch msgType {
+	case MessageTypeText, MessageTypePost, MessageTypeImage,
+		MessageTypeInteractive, MessageTypeShareChat, MessageTypeShareUser,
+		MessageTypeFile, MessageTypeAudio, MessageTypeMedia:
+		return true
+	}
+	return false
+}
+
+// 函数发送一次请求并检查响应码。
+// compress 为 true 时先以 gzip 压缩发送，服务端拒绝时改为不压缩重试。
+func postTyped[T any](ctx context.Context, c BotClient, u string, bs []byte, compress bool) (SendResponse[T], error) {
+	if compress {
+		gz, err := gzipBytes(bs)
+		if err != nil {
+			c.logger().ErrorContext(ctx, "请求体压缩失败", slog.Any("err", err))
+			return SendResponse[T]{}, err
+		}
+		data, err := postBody[T](ctx, c, u, gz, "gzip")
+		if !rejectsGzip(err) {
+			return data, err
+		}
+		c.logger().WarnContext(ctx, "服务端不接受压缩请求，改为不压缩重试", slog.Any("err", err))
+	}
+	return postBody[T](ctx, c, u, bs, "")
+}
+
+// 函数以指定的 Content-Encoding 发送请求体，并检查响应码。encoding 为空时不设置。
+func postBody[T any](ctx context.Context, c BotClient, u string, bs []byte, encoding string) (SendResponse[T], error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(bs))
 	if err != nil {
 		c.logger().ErrorContext(ctx, "请求创建失败", slog.Any("err", err))
-		return err
+		return SendResponse[T]{}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
 
-	resp, err := c.client().Do(req)
+	resp, body, err := c.roundTrip(ctx, req)
 	if err != nil {
-		c.logger().ErrorContext(ctx, "请求发送失败", slog.Any("err", err))
+		return SendResponse[T]{}, err
+	}
+	data, err := checkSend[T](ctx, c, resp, body)
+	if err != nil && c.PreserveResponseBody {
+		err = preserveBody(err, resp, body)
+	}
+	return data, err
+}
+
+// 函数检查发送信息的响应，并将响应数据解析为 T 类型。
+func checkSend[T any](ctx context.Context, c BotClient, resp *http.Response, bs []byte) (SendResponse[T], error) {
+	var data SendResponse[T]
+	if c.SuccessFunc != nil {
+		c.unmarshal(bs, &data)
+		if err := c.SuccessFunc(resp.StatusCode, bs); err != nil {
+			c.logger().ErrorContext(ctx, "响应异常", slog.Any("err", err))
+			return data, err
+		}
+		return data, nil
+	}
+
+	err := c.checkResponse(ctx, resp, bs, &data)
+	if err != nil {
+		return SendResponse[T]{}, err
+	}
+	if data.Code != 0 {
+		c.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.Code), slog.String("msg", data.Msg))
+		return data, APIError{Code: data.Code, Msg: data.Msg}
+	}
+	if data.StatusCode != 0 {
+		c.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.StatusCode), slog.String("msg", data.StatusMessage))
+		return data, APIError{Code: data.StatusCode, Msg: data.StatusMessage}
+	}
+	return data, nil
+}
+
+// 函数将响应状态码、响应头与响应内容附加到错误上，返回 [HTTPError]。
+// err 中已经包含 [HTTPError] 时原样返回。
+func preserveBody(err error, resp *http.Response, body []byte) error {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
 		return err
 	}
+	return HTTPError{StatusCode: resp.StatusCode, Header: resp.Header, Body: body, Err: err}
+}
+
+// 函数判断错误是否表示服务端不接受压缩请求。
+// 只有 415 表示不支持的内容编码，其他 400 或 [CodeBadRequest] 等错误与压缩无关，不会改为不压缩重试。
+func rejectsGzip(err error) bool {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusUnsupportedMediaType
+	}
+	return false
+}
+
+// 压缩请求体的大小阈值，单位为字节
+const compressThreshold = 1 << 10
+
+// 函数以 gzip 压缩数据。
+func gzipBytes(bs []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(bs); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 方法发送请求，并返回响应与读取的响应内容。返回时响应体已关闭。
+func (c BotClient) roundTrip(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	for k, v := range c.Headers {
+		key := http.CanonicalHeaderKey(k)
+		if key == "Content-Type" {
+			continue
+		}
+		req.Header[key] = slices.Clone(v)
+	}
+	req.Header.Set("User-Agent", c.userAgent())
+	if c.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", c.AcceptLanguage)
+	}
+
+	client, err := c.client()
+	if err != nil {
+		c.logger().ErrorContext(ctx, "http client 配置错误", slog.Any("err", err))
+		return nil, nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// http.Client 出错时响应体已关闭，但自定义的 Doer 可能同时返回响应与错误
+		if resp != nil && resp.Body != nil {
+			resp.Body.Close()
+		}
+		err = c.redactErr(err)
+		c.logger().ErrorContext(ctx, "请求发送失败", slog.Any("err", err))
+		return nil, nil, err
+	}
 	defer resp.Body.Close()
 
-	bs, err = io.ReadAll(resp.Body)
+	// ctx 结束时关闭响应体，避免读取缓慢的响应时超出截止时间
+	stop := context.AfterFunc(ctx, func() { resp.Body.Close() })
+	defer stop()
+
+	limit := c.maxResponseBytes()
+	bs, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
 		c.logger().ErrorContext(ctx, "响应读取失败", slog.Any("err", err))
+		return nil, nil, err
+	}
+	if int64(len(bs)) > limit {
+		c.logger().ErrorContext(ctx, "响应内容过大", slog.Int("status-code", resp.StatusCode), slog.Int64("limit", limit))
+		return nil, nil, fmt.Errorf("%w: 响应状态码 %d, 超过 %d 字节", ErrResponseTooLarge, resp.StatusCode, limit)
+	}
+
+	c.debugBody(ctx, "响应内容", slog.Int("status-code", resp.StatusCode), slog.String("body", string(bs)))
+	return resp, bs, nil
+}
+
+// 方法发送请求，并将 json 响应解析到 data 中。
+func (c BotClient) do(ctx context.Context, req *http.Request, data any) error {
+	resp, bs, err := c.roundTrip(ctx, req)
+	if err != nil {
 		return err
 	}
+	return c.checkResponse(ctx, resp, bs, data)
+}
 
+// 方法检查响应状态码与响应类型，并将 json 响应内容解析到 data 中。
+func (c BotClient) checkResponse(ctx context.Context, resp *http.Response, bs []byte, data any) error {
 	if resp.StatusCode != http.StatusOK {
-		c.logger().ErrorContext(ctx, "响应状态错误", slog.Int("status-code", resp.StatusCode))
-		return fmt.Errorf("响应状态错误: %d", resp.StatusCode)
+		c.logger().ErrorContext(ctx, "响应状态错误", slog.Int("status-code", resp.StatusCode), slog.Any("body", bytes.NewReader(bs)))
+		return HTTPError{StatusCode: resp.StatusCode, Header: resp.Header, Body: bs}
 	}
-	if mt := resp.Header.Get("Content-Type"); !strings.HasPrefix(mt, "application/json") {
+	if mt := resp.Header.Get("Content-Type"); !isJSON(mt) {
 		c.logger().ErrorContext(ctx, "响应类型错误", slog.String("content-type", mt), slog.Any("body", bytes.NewReader(bs)))
-		return fmt.Errorf("响应类型错误: %s", mt)
+		return fmt.Errorf("%w: %s, 响应内容: %q", ErrUnexpectedContentType, mt, snippet(bs))
 	}
 
-	var data SendResponse[struct{}]
-	err = json.Unmarshal(bs, &data)
+	err := c.unmarshal(bs, data)
 	if err != nil {
 		c.logger().ErrorContext(ctx, "响应解析失败", slog.Any("err", err), slog.Any("body", bytes.NewReader(bs)))
 		return err
 	}
-	if data.Code != 0 {
-		c.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.Code), slog.String("msg", data.Msg))
-		return fmt.Errorf("响应异常: %d %s", data.Code, data.Msg)
+	return nil
+}
+
+// 函数计算签名。
+// 签名为以 timestamp + "\n" + secret 为密钥，对空字符串进行 HmacSHA256 计算后的 Base64 编码。
+func sign(timestamp, secret string) string {
+	h := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// 方法在 ctx 没有截止时间时，为其附加默认超时时间。
+func (c BotClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.Timeout <= 0 {
+		return ctx, func() {}
 	}
+	return context.WithTimeout(ctx, c.Timeout)
+}
 
-	c.logger().InfoContext(ctx, "消息发送成功")
-	return nil
+// 方法在开启 LogBody 时以 Debug 级别记录日志。
+func (c BotClient) debugBody(ctx context.Context, msg string, args ...any) {
+	if c.LogBody {
+		c.logger().DebugContext(ctx, msg, args...)
+	}
+}
+
+// 方法将地址中的令牌替换为 ***。
+func (c BotClient) redact(u string) string {
+	if c.Token == "" {
+		return u
+	}
+	return strings.ReplaceAll(u, url.PathEscape(c.Token), "***")
+}
+
+// 方法将请求错误中地址包含的令牌替换为 ***。
+// http.Client 返回的 *url.Error 在错误信息中带有完整的请求地址。
+func (c BotClient) redactErr(err error) error {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	redacted := *ue
+	redacted.URL = c.redact(ue.URL)
+	return &redacted
+}
+
+// 错误信息中响应内容片段的最大长度
+const maxSnippetBytes = 256
+
+// 函数截取响应内容的开头部分，用于错误信息。
+func snippet(bs []byte) string {
+	if len(bs) > maxSnippetBytes {
+		bs = bs[:maxSnippetBytes]
+	}
+	return strings.ToValidUTF8(string(bs), "")
 }
 
-func (c BotClient) logger() *slog.Logger { return cmp.Or(c.Logger, slog.Default()) }
-func (c BotClient) client() *http.Client { return cmp.Or(c.Client, http.DefaultClient) }
-func (c BotClient) baseURL() string      { return cmp.Or(c.BaseURL, "https://open.feishu.cn") }
+// 默认值
+const (
+	defaultBaseURL     = "https://open.feishu.cn" // 接口基础地址
+	defaultHookVersion = "v2"                     // webhook 接口版本
+)
+
+func (c BotClient) baseURL() string   { return cmp.Or(c.BaseURL, defaultBaseURL) }
+func (c BotClient) userAgent() string { return cmp.Or(c.UserAgent, "kvii-bot/1.0") }
+
+// 响应内容的默认大小上限
+const defaultMaxResponseBytes = 1 << 20
+
+func (c BotClient) maxResponseBytes() int64 {
+	if c.MaxResponseBytes <= 0 {
+		return defaultMaxResponseBytes
+	}
+	return c.MaxResponseBytes
+}
+
+func (c BotClient) logger() *slog.Logger {
+	l := cmp.Or(c.Logger, slog.Default())
+	if c.LogAttrsFromContext == nil {
+		return l
+	}
+	return slog.New(contextHandler{l.Handler(), c.LogAttrsFromContext})
+}
+
+func (c BotClient) marshal(v any) ([]byte, error) {
+	if c.Marshal != nil {
+		return c.Marshal(v)
+	}
+	return json.Marshal(v)
+}
+
+func (c BotClient) unmarshal(data []byte, v any) error {
+	if c.Unmarshal != nil {
+		return c.Unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// 执行 http 请求的接口。*http.Client 实现了该接口。
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// 方法返回执行请求使用的 Doer。
+func (c BotClient) client() (Doer, error) {
+	if c.ProxyURL != "" {
+		if c.Doer != nil || c.Client != nil || c.Transport != nil {
+			return nil, ErrProxyConflict
+		}
+		return proxyClient(c.ProxyURL)
+	}
+	if c.Doer != nil {
+		return c.Doer, nil
+	}
+	if c.Client == nil && c.Transport != nil {
+		return &http.Client{Transport: c.Transport}, nil
+	}
+	return cmp.Or(c.Client, http.DefaultClient), nil
+}
+
+// 按代理地址缓存的 http client，以便复用连接。
+var proxyClients sync.Map
+
+// 函数返回使用指定代理的 http client。
+func proxyClient(proxyURL string) (*http.Client, error) {
+	if v, ok := proxyClients.Load(proxyURL); ok {
+		return v.(*http.Client), nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("代理地址错误: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("代理地址错误: %q", proxyURL)
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyURL(u)
+	v, _ := proxyClients.LoadOrStore(proxyURL, &http.Client{Transport: t})
+	return v.(*http.Client), nil
+}
+
+// 从 ctx 中提取日志属性的 slog.Handler
+type contextHandler struct {
+	slog.Handler
+	attrs func(ctx context.Context) []slog.Attr
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(h.attrs(ctx)...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs), h.attrs}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name), h.attrs}
+}
+
+// 函数判断响应类型是否为 json。忽略大小写，兼容 text/json 与 charset 等参数。
+func isJSON(contentType string) bool {
+	mt := strings.ToLower(strings.TrimSpace(contentType))
+	return strings.HasPrefix(mt, "application/json") || strings.HasPrefix(mt, "text/json")
+}
@@ -1,23 +1,53 @@
 package feishu
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/kvii/bot/internal/errmatch"
 )
 
+// 函数启动使用 handler 的测试服务器，并返回指向该服务器的客户端。
+// 客户端使用测试令牌，详细模式下输出日志。服务器在测试结束时关闭。
+func newTestServer(t *testing.T, handler http.Handler) (BotClient, *httptest.Server) {
+	t.Helper()
+
+	logger := NopLogger()
+	if testing.Verbose() {
+		logger = slog.Default()
+	}
+
+	s := httptest.NewServer(handler)
+	t.Cleanup(s.Close)
+
+	return BotClient{
+		Client:  s.Client(),
+		Logger:  logger,
+		BaseURL: s.URL,
+		Token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+	}, s
+}
+
 func TestBotClientSendText(t *testing.T) {
 	var logger *slog.Logger
 	if testing.Verbose() {
 		logger = slog.Default()
 	} else {
-		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		logger = NopLogger()
 	}
 
 	var mux http.ServeMux
@@ -79,7 +109,7 @@ func TestBotClientSendText(t *testing.T) {
 			},
 			ctx: context.Background(),
 			msg: "测试",
-			err: ErrContains("Bad Request"),
+			err: errmatch.Contains("Bad Request"),
 		},
 	}
 	for _, tc := range testCases {
@@ -92,17 +122,1872 @@ func TestBotClientSendText(t *testing.T) {
 	}
 }
 
-// ErrContains 返回一个用于判断错误信息是否包含指定字符串的错误对象
-func ErrContains(s string) error {
-	return contains{s}
+func TestBotClient_SendPost(t *testing.T) {
+	var got []byte
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendPost(context.Background(), "项目更新通知", [][]PostElement{
+		{
+			{Tag: PostTagText, Text: "项目有更新: "},
+			{Tag: PostTagLink, Text: "请查看", Href: "http://www.example.com/"},
+			{Tag: PostTagAt, UserID: "all"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	expect := `{"msg_type":"post","content":{"post":{"zh_cn":{"title":"项目更新通知","content":[[{"tag":"text","text":"项目有更新: "},{"tag":"a","text":"请查看","href":"http://www.example.com/"},{"tag":"at","user_id":"all"}]]}}}}`
+	if string(got) != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestBotClient_SendPostMultiLang(t *testing.T) {
+	var got []byte
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name   string                 // 测试项目
+		posts  map[Locale]PostContent // 各语言内容
+		expect string                 // 预期请求体
+		err    error                  // 预期错误
+	}{
+		{
+			name: "normal",
+			posts: map[Locale]PostContent{
+				LocaleZhCN: {Title: "项目更新通知", Content: [][]PostElement{{{Tag: PostTagText, Text: "项目有更新"}}}},
+				LocaleEnUS: {Title: "Project update", Content: [][]PostElement{{{Tag: PostTagText, Text: "Project updated"}}}},
+			},
+			expect: `{"msg_type":"post","content":{"post":{"en_us":{"title":"Project update","content":[[{"tag":"text","text":"Project updated"}]]},"zh_cn":{"title":"项目更新通知","content":[[{"tag":"text","text":"项目有更新"}]]}}}}`,
+			err:    nil,
+		},
+		{
+			name:  "empty",
+			posts: nil,
+			err:   errmatch.Contains("富文本内容为空"),
+		},
+		{
+			name:  "unknown locale",
+			posts: map[Locale]PostContent{"zh-CN": {Title: "项目更新通知"}},
+			err:   errmatch.Contains("富文本语言错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := client.SendPostMultiLang(context.Background(), tc.posts)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err == nil && string(got) != tc.expect {
+				t.Fatalf("expect %s, got %s", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := sign("1599360473", "demo")
+	expect := "l1N0gAcBjdwBvGm1xMjOF0XSyaLRpR7tuO5dHfhAYc8="
+	if got != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestBotClient_Secret(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		var msg Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		if msg.Timestamp == "" || msg.Sign != sign(msg.Timestamp, "demo") {
+			w.Write([]byte(`{ "code": 19021, "data": {}, "msg": "sign match fail or timestamp is not within one hour from current time" }`))
+			return
+		}
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name   string // 测试项目
+		secret string // 签名密钥
+		err    error  // 预期错误
+	}{
+		{
+			name:   "normal",
+			secret: "demo",
+			err:    nil,
+		},
+		{
+			name:   "sign mismatch",
+			secret: "wrong",
+			err:    APIError{Code: CodeSignMismatch, Msg: "sign match fail or timestamp is not within one hour from current time"},
+		},
+		{
+			name:   "no secret",
+			secret: "",
+			err:    errmatch.Contains("sign match fail"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.Secret = tc.secret
+			err := client.SendText(context.Background(), "测试")
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendCard(t *testing.T) {
+	var got []byte
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendCard(context.Background(), Card{
+		Config: &CardConfig{WideScreenMode: true},
+		Header: &CardHeader{Title: CardText{Tag: CardTextTagPlainText, Content: "告警"}, Template: "red"},
+		Elements: []CardElement{
+			{Tag: CardTagMarkdown, Content: "**服务** 响应超时"},
+			{Tag: CardTagAction, Actions: []CardElement{
+				{Tag: CardTagButton, Text: &CardText{Tag: CardTextTagPlainText, Content: "查看"}, URL: "https://example.com", Type: "primary"},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	expect := `{"msg_type":"interactive","card":{"config":{"wide_screen_mode":true},"header":{"title":{"tag":"plain_text","content":"告警"},"template":"red"},"elements":[{"tag":"markdown","content":"**服务** 响应超时"},{"tag":"action","actions":[{"tag":"button","text":{"tag":"plain_text","content":"查看"},"url":"https://example.com","type":"primary"}]}]}}`
+	if string(got) != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestBotClient_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		<-done
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	client, _ := newTestServer(t, &mux)
+	t.Cleanup(func() { close(done) })
+
+	client.Timeout = 10 * time.Millisecond
+
+	err := client.SendText(context.Background(), "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// ctx 自带的截止时间优先
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	client.Timeout = time.Hour
+	err = client.SendText(ctx, "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestBotClient_LogBody(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	for _, logBody := range []bool{true, false} {
+		var buf bytes.Buffer
+		client := BotClient{
+			Client:  s.Client(),
+			Logger:  slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			BaseURL: s.URL,
+			Token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+			LogBody: logBody,
+		}
+		err := client.SendText(context.Background(), "敏感内容")
+		if err != nil {
+			t.Fatalf("expect nil, got %v", err)
+		}
+
+		logs := buf.String()
+		if strings.Contains(logs, "85d09ddb-5937-46e7-8628-d7959a93e3af") {
+			t.Fatalf("token should be redacted: %s", logs)
+		}
+		if strings.Contains(logs, "敏感内容") != logBody {
+			t.Fatalf("LogBody is %v, got logs: %s", logBody, logs)
+		}
+		if strings.Contains(logs, "***") != logBody {
+			t.Fatalf("LogBody is %v, got logs: %s", logBody, logs)
+		}
+	}
+}
+
+func TestBotClient_Transport(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.Header.Get("X-Trace-Id") != "trace" {
+			w.Write([]byte(`{ "code": 9499, "msg": "missing trace id", "data": {} }`))
+			return
+		}
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	client := BotClient{
+		Logger:  logger,
+		BaseURL: s.URL,
+		Token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r.Header.Set("X-Trace-Id", "trace")
+			return s.Client().Transport.RoundTrip(r)
+		}),
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+}
+
+// 函数形式的 http.RoundTripper
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestBotClient_SendCardRaw(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "code": 0, "data": { "message_id": "om_dc13264520392913993dd051dba21dcf" }, "msg": "success" }`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	resp, err := client.SendCardRaw(context.Background(), Card{
+		Elements: []CardElement{{Tag: CardTagMarkdown, Content: "测试"}},
+	})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if id := resp.Data["message_id"]; id != "om_dc13264520392913993dd051dba21dcf" {
+		t.Fatalf("unexpected message_id: %v", id)
+	}
+}
+
+func TestBotClient_UnexpectedContentType(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<html><body>404 page not found</body></html>`))
+		w.Write([]byte(strings.Repeat("-", 1024)))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrUnexpectedContentType) {
+		t.Fatalf("expect %v, got %v", ErrUnexpectedContentType, err)
+	}
+	if !errors.Is(errmatch.Contains("404 page not found"), err) {
+		t.Fatalf("expect error contains body, got %v", err)
+	}
+	if len(err.Error()) > 2*maxSnippetBytes {
+		t.Fatalf("error too long: %d", len(err.Error()))
+	}
+}
+
+func TestBotClient_OnSend(t *testing.T) {
+	var retried atomic.Bool
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("token") == "retry" && !retried.Swap(true) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	type outcome struct {
+		msgType  string
+		duration time.Duration
+		err      error
+	}
+	var outcomes []outcome
+	client.OnSend = func(msgType string, duration time.Duration, err error) {
+		outcomes = append(outcomes, outcome{msgType, duration, err})
+	}
+
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	client.Token = ""
+	err = client.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrNeedToken) {
+		t.Fatalf("expect %v, got %v", ErrNeedToken, err)
+	}
+
+	if len(outcomes) != 2 {
+		t.Fatalf("expect 2 outcomes, got %d", len(outcomes))
+	}
+	if o := outcomes[0]; o.msgType != MessageTypeText || o.duration <= 0 || o.err != nil {
+		t.Fatalf("unexpected outcome: %+v", o)
+	}
+	if o := outcomes[1]; o.msgType != MessageTypeText || o.duration != 0 || o.err != ErrNeedToken {
+		t.Fatalf("unexpected outcome: %+v", o)
+	}
+
+	// 耗时只统计最后一次请求，不包含重试间隔
+	const delay = 300 * time.Millisecond
+	client.Token = "retry"
+	client.Retry = RetryConfig{MaxAttempts: 2, BaseDelay: delay}
+	start := time.Now()
+	err = client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if wall := time.Since(start); wall < delay {
+		t.Fatalf("expect retry delay, got %v", wall)
+	}
+	if o := outcomes[2]; o.duration <= 0 || o.duration >= delay {
+		t.Fatalf("expect duration of the last request, got %v", o.duration)
+	}
+}
+
+func TestBotClient_SendShare(t *testing.T) {
+	var got []byte
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name   string                                     // 测试项目
+		send   func(ctx context.Context, id string) error // 发送方法
+		id     string                                     // ID
+		expect string                                     // 预期请求内容
+		err    error                                      // 预期错误
+	}{
+		{
+			name:   "share chat",
+			send:   client.SendShareChat,
+			id:     "oc_f5b1a7eb27ae2c7b6adc2a74faf339ff",
+			expect: `{"msg_type":"share_chat","content":{"share_chat_id":"oc_f5b1a7eb27ae2c7b6adc2a74faf339ff"}}`,
+			err:    nil,
+		},
+		{
+			name:   "share chat empty id",
+			send:   client.SendShareChat,
+			id:     "",
+			expect: "",
+			err:    ErrEmptyID,
+		},
+		{
+			name:   "share user",
+			send:   client.SendShareUser,
+			id:     "ou_7d8a6e6df7621556ce0d21922b676706ccs",
+			expect: `{"msg_type":"share_user","content":{"user_id":"ou_7d8a6e6df7621556ce0d21922b676706ccs"}}`,
+			err:    nil,
+		},
+		{
+			name:   "share user empty id",
+			send:   client.SendShareUser,
+			id:     "",
+			expect: "",
+			err:    ErrEmptyID,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := tc.send(context.Background(), tc.id)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if string(got) != tc.expect {
+				t.Fatalf("expect %s, got %s", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	var err error = APIError{Code: CodeBadRequest, Msg: "Bad Request"}
+
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeBadRequest {
+		t.Fatalf("expect APIError %d, got %v", CodeBadRequest, err)
+	}
+	if !errors.Is(errmatch.Contains("Bad Request"), err) {
+		t.Fatalf("expect error contains msg, got %v", err)
+	}
+}
+
+func TestBotClient_UserAgent(t *testing.T) {
+	var got string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name      string // 测试项目
+		userAgent string // User-Agent
+		expect    string // 预期 User-Agent
+	}{
+		{name: "default", userAgent: "", expect: "kvii-bot/1.0"},
+		{name: "custom", userAgent: "alert-service/2.3", expect: "alert-service/2.3"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.UserAgent = tc.userAgent
+			err := client.SendText(context.Background(), "测试")
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+			if got != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_DryRun(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 不应发送任何请求
+	client := BotClient{
+		Client:  &http.Client{Transport: errTransport{t}},
+		Logger:  logger,
+		BaseURL: "http://example.com",
+		Token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+		DryRun:  true,
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	client.Token = ""
+	err = client.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrNeedToken) {
+		t.Fatalf("expect %v, got %v", ErrNeedToken, err)
+	}
 }
 
-type contains struct{ string }
+// 不允许发送请求的 http.RoundTripper
+type errTransport struct{ t *testing.T }
 
-func (e contains) Error() string {
-	return fmt.Sprintf("err should contains %q", e.string)
+func (e errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	e.t.Fatal("unexpected request")
+	return nil, errors.New("unexpected request")
 }
 
-func (e contains) Is(err error) bool {
-	return err != nil && strings.Contains(err.Error(), e.string)
+func TestBotClient_HookVersion(t *testing.T) {
+	var got string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/{version}/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		got = r.PathValue("version")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name        string // 测试项目
+		hookVersion string // webhook 接口版本
+		expect      string // 预期版本
+	}{
+		{name: "default", hookVersion: "", expect: "v2"},
+		{name: "v3", hookVersion: "v3", expect: "v3"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.HookVersion = tc.hookVersion
+			err := client.SendText(context.Background(), "测试")
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+			if got != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestDefaultPooledClient(t *testing.T) {
+	c := DefaultPooledClient()
+	tr, ok := c.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("unexpected transport: %T", c.Transport)
+	}
+	if tr.MaxIdleConnsPerHost != 20 || tr.IdleConnTimeout != 90*time.Second {
+		t.Fatalf("unexpected transport config: %d %v", tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+	if tr == http.DefaultTransport {
+		t.Fatalf("should not modify http.DefaultTransport")
+	}
+}
+
+func TestBotClient_LogAttrsFromContext(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	type traceKey struct{}
+	var buf bytes.Buffer
+	client := BotClient{
+		Client:  s.Client(),
+		Logger:  slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		BaseURL: s.URL,
+		Token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+		LogBody: true,
+		LogAttrsFromContext: func(ctx context.Context) []slog.Attr {
+			id, _ := ctx.Value(traceKey{}).(string)
+			return []slog.Attr{slog.String("trace-id", id)}
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "abc123")
+	err := client.SendText(ctx, "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expect multiple log lines, got %q", lines)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "trace-id=abc123") {
+			t.Fatalf("expect trace-id in every line, got %q", line)
+		}
+	}
+}
+
+func TestBotClient_HTTPError(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("too many requests"))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendText(context.Background(), "测试")
+
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expect HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expect status %d, got %d", http.StatusTooManyRequests, httpErr.StatusCode)
+	}
+	if v := httpErr.Header.Get("Retry-After"); v != "30" {
+		t.Fatalf("expect Retry-After 30, got %q", v)
+	}
+	if string(httpErr.Body) != "too many requests" {
+		t.Fatalf("unexpected body: %s", httpErr.Body)
+	}
+}
+
+func TestBotClient_SendCardTemplate(t *testing.T) {
+	var got []byte
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendCardTemplate(context.Background(), "ctp_AAyVLS6Q37cL", map[string]any{"service": "api"})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	expect := `{"msg_type":"interactive","card":{"type":"template","data":{"template_id":"ctp_AAyVLS6Q37cL","template_variable":{"service":"api"}}}}`
+	if string(got) != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestBotClient_ProxyURL(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 作为代理的测试服务器，普通代理请求的 URL 为完整地址
+	var got string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.String()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	}))
+	t.Cleanup(proxy.Close)
+
+	testCases := []struct {
+		name   string    // 测试项目
+		client BotClient // 客户端
+		err    error     // 预期错误
+	}{
+		{
+			name:   "normal",
+			client: BotClient{ProxyURL: proxy.URL},
+			err:    nil,
+		},
+		{
+			name:   "malformed",
+			client: BotClient{ProxyURL: "://proxy"},
+			err:    errmatch.Contains("代理地址错误"),
+		},
+		{
+			name:   "missing host",
+			client: BotClient{ProxyURL: "proxy.example.com"},
+			err:    errmatch.Contains("代理地址错误"),
+		},
+		{
+			name:   "conflict",
+			client: BotClient{ProxyURL: proxy.URL, Client: proxy.Client()},
+			err:    ErrProxyConflict,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = ""
+			tc.client.Logger = logger
+			tc.client.BaseURL = "http://bot.example.com"
+			tc.client.Token = "85d09ddb-5937-46e7-8628-d7959a93e3af"
+			err := tc.client.SendText(context.Background(), "测试")
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err == nil && !strings.HasPrefix(got, "http://bot.example.com/") {
+				t.Fatalf("expect request through proxy, got %q", got)
+			}
+		})
+	}
+}
+
+func TestBotClient_Retry(t *testing.T) {
+	// 每个令牌前两次请求返回限流
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		token := r.PathValue("token")
+		mu.Lock()
+		counts[token]++
+		n := counts[token]
+		mu.Unlock()
+
+		if n <= 2 && token == "status_429" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if n <= 2 {
+			w.Write([]byte(`{"code":11232,"data":{},"msg":"frequency limited"}`))
+			return
+		}
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name  string          // 测试项目
+		token string          // 机器人令牌
+		retry RetryConfig     // 重试配置
+		ctx   context.Context // ctx 对象
+		err   error           // 预期错误
+	}{
+		{
+			name:  "no retry",
+			token: "no_retry",
+			retry: RetryConfig{},
+			ctx:   context.Background(),
+			err:   APIError{Code: CodeFreqLimited, Msg: "frequency limited"},
+		},
+		{
+			name:  "retry exhausted",
+			token: "retry_exhausted",
+			retry: RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			ctx:   context.Background(),
+			err:   APIError{Code: CodeFreqLimited, Msg: "frequency limited"},
+		},
+		{
+			name:  "retry succeeded",
+			token: "retry_succeeded",
+			retry: RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			ctx:   context.Background(),
+			err:   nil,
+		},
+		{
+			name:  "status 429",
+			token: "status_429",
+			retry: RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			ctx:   context.Background(),
+			err:   nil,
+		},
+		{
+			name:  "canceled",
+			token: "canceled",
+			retry: RetryConfig{MaxAttempts: 3, BaseDelay: time.Hour},
+			ctx:   canceled,
+			err:   context.Canceled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.Token = tc.token
+			client.Retry = tc.retry
+			err := client.SendText(tc.ctx, "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestBotClient_SlowBody(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 响应体每隔一段时间写入一个字节，且不感知 ctx
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			for {
+				time.Sleep(5 * time.Millisecond)
+				if _, err := pw.Write([]byte(" ")); err != nil {
+					return
+				}
+			}
+		}()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       pr,
+		}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := BotClient{
+		Logger:    logger,
+		BaseURL:   "http://bot.example.com",
+		Token:     "85d09ddb-5937-46e7-8628-d7959a93e3af",
+		Transport: transport,
+	}
+	err := client.SendText(ctx, "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestBotClient_Marshal(t *testing.T) {
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	var marshaled, unmarshaled int
+	client.Marshal = func(v any) ([]byte, error) {
+		marshaled++
+		return json.Marshal(v)
+	}
+	client.Unmarshal = func(data []byte, v any) error {
+		unmarshaled++
+		return json.Unmarshal(data, v)
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if marshaled != 1 || unmarshaled != 1 {
+		t.Fatalf("expect custom encoder to be used once, got marshal %d, unmarshal %d", marshaled, unmarshaled)
+	}
+}
+
+func TestBotClient_Headers(t *testing.T) {
+	var got http.Header
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	client.Headers = http.Header{
+		"X-Trace-Id":   {"trace"},
+		"content-type": {"text/plain"},
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if v := got.Get("X-Trace-Id"); v != "trace" {
+		t.Fatalf("expect X-Trace-Id %q, got %q", "trace", v)
+	}
+	if v := got.Get("Content-Type"); v != "application/json" {
+		t.Fatalf("expect Content-Type %q, got %q", "application/json", v)
+	}
+}
+
+func TestSendTyped(t *testing.T) {
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{"message_id":"om_xxx"},"msg":"success"}`))
+	}))
+
+	type data struct {
+		MessageID string `json:"message_id"`
+	}
+	resp, err := SendTyped[data](context.Background(), client, Message{
+		MsgType: MessageTypeText,
+		Content: TextMessage{Text: "测试"},
+	})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if resp.Data.MessageID != "om_xxx" {
+		t.Fatalf("expect %q, got %q", "om_xxx", resp.Data.MessageID)
+	}
+}
+
+func TestBotClient_StatusCode(t *testing.T) {
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 19021, "StatusMessage": "sign match fail or timestamp is not within one hour from current time", "code": 0, "data": {}, "msg": "" }`))
+	}))
+
+	err := client.SendText(context.Background(), "测试")
+
+	expect := APIError{Code: CodeSignMismatch, Msg: "sign match fail or timestamp is not within one hour from current time"}
+	if !errors.Is(err, expect) {
+		t.Fatalf("expect %v, got %v", expect, err)
+	}
+}
+
+func TestBotClient_SendJSON(t *testing.T) {
+	var got []byte
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if bytes.Contains(got, []byte("unknown")) {
+			w.Write([]byte(`{"code":9499,"data":{},"msg":"Bad Request"}`))
+			return
+		}
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	testCases := []struct {
+		name string          // 测试项目
+		raw  json.RawMessage // 请求体
+		err  error           // 预期错误
+	}{
+		{
+			name: "normal",
+			raw:  json.RawMessage(`{"msg_type": "text", "content": {"text": "测试"}}`),
+			err:  nil,
+		},
+		{
+			name: "invalid json",
+			raw:  json.RawMessage(`{"msg_type":`),
+			err:  errmatch.Contains("json 格式错误"),
+		},
+		{
+			name: "not object",
+			raw:  json.RawMessage(`null`),
+			err:  errmatch.Contains("json 格式错误"),
+		},
+		{
+			name: "non-string type",
+			raw:  json.RawMessage(`{"msg_type": 1}`),
+			err:  errmatch.Contains("msg_type 需要为非空字符串"),
+		},
+		{
+			name: "missing type",
+			raw:  json.RawMessage(`{"content": {}}`),
+			err:  errmatch.Contains("msg_type 需要为非空字符串"),
+		},
+		{
+			name: "api error",
+			raw:  json.RawMessage(`{"msg_type":"unknown"}`),
+			err:  APIError{Code: CodeBadRequest, Msg: "Bad Request"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := client.SendJSON(context.Background(), tc.raw)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err == nil && string(got) != string(tc.raw) {
+				t.Fatalf("expect %s, got %s", tc.raw, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendJSON_Secret(t *testing.T) {
+	var got map[string]any
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	client.Secret = "demo"
+	err := client.SendJSON(context.Background(), json.RawMessage(`{"msg_type": "text", "content": {"text": "测试"}}`))
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	timestamp, _ := got["timestamp"].(string)
+	if got["sign"] != sign(timestamp, "demo") {
+		t.Fatalf("expect signed body, got %v", got)
+	}
+	if got["msg_type"] != "text" {
+		t.Fatalf("expect msg_type text, got %v", got["msg_type"])
+	}
+}
+
+func TestBotClient_ContentTypeVariants(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	for _, contentType := range []string{
+		"application/json",
+		"application/json; charset=utf-8",
+		"Application/JSON",
+		"text/json",
+		"TEXT/JSON; charset=UTF-8",
+	} {
+		t.Run(contentType, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", contentType)
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+			}))
+			t.Cleanup(s.Close)
+
+			client := BotClient{
+				Client:  s.Client(),
+				Logger:  logger,
+				BaseURL: s.URL,
+				Token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+			}
+			err := client.SendText(context.Background(), "测试")
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendTextf(t *testing.T) {
+	var got []byte
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	err := client.SendTextf(context.Background(), "服务 %s 异常, 错误数 %d", "api", 3)
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	expect := `{"msg_type":"text","content":{"text":"服务 api 异常, 错误数 3"}}`
+	if string(got) != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestBotClient_RequestURL(t *testing.T) {
+	testCases := []struct {
+		name   string    // 测试项目
+		client BotClient // 客户端
+		want   string    // 预期地址
+		err    error     // 预期错误
+	}{
+		{
+			name:   "default",
+			client: BotClient{Token: "85d09ddb-5937-46e7-8628-d7959a93e3af"},
+			want:   "https://open.feishu.cn/open-apis/bot/v2/hook/***",
+		},
+		{
+			name:   "custom",
+			client: BotClient{BaseURL: "https://open.larksuite.com", HookVersion: "v1", Token: "xxx"},
+			want:   "https://open.larksuite.com/open-apis/bot/v1/hook/***",
+		},
+		{
+			name:   "need token",
+			client: BotClient{},
+			err:    ErrNeedToken,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.client.RequestURL()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if got != tc.want {
+				t.Fatalf("expect %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_UnsupportedMessageType(t *testing.T) {
+	var called bool
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	err := client.Send(context.Background(), Message{MsgType: "sticker", Content: map[string]string{"file_key": "xxx"}})
+	if !errors.Is(err, ErrUnsupportedMessageType) {
+		t.Fatalf("expect %v, got %v", ErrUnsupportedMessageType, err)
+	}
+	if called {
+		t.Fatal("expect no request to be sent")
+	}
+
+	// SendJSON 不检查信息类型
+	err = client.SendJSON(context.Background(), json.RawMessage(`{"msg_type":"sticker","content":{"file_key":"xxx"}}`))
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+}
+
+func TestBotClient_BeforeSend(t *testing.T) {
+	var got []byte
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	errAbort := errors.New("abort")
+	testCases := []struct {
+		name   string                                        // 测试项目
+		hook   func(ctx context.Context, msg *Message) error // 发送前钩子
+		expect string                                        // 预期请求体
+		err    error                                         // 预期错误
+	}{
+		{
+			name: "footer",
+			hook: func(ctx context.Context, msg *Message) error {
+				msg.Content = TextMessage{Text: msg.Content.(TextMessage).Text + "\n-- 来自监控系统"}
+				return nil
+			},
+			expect: `{"msg_type":"text","content":{"text":"测试\n-- 来自监控系统"}}`,
+			err:    nil,
+		},
+		{
+			name: "abort",
+			hook: func(ctx context.Context, msg *Message) error {
+				return errAbort
+			},
+			err: errAbort,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			client := base
+			client.BeforeSend = tc.hook
+			err := client.SendText(context.Background(), "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if string(got) != tc.expect {
+				t.Fatalf("expect %s, got %s", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_Compress(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 路径 /reject 下的服务端不接受压缩请求，路径 /invalid 下的服务端对压缩请求返回与压缩无关的 400
+	var encodings []string
+	var bodies []string
+	handler := func(status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			encoding := r.Header.Get("Content-Encoding")
+			encodings = append(encodings, encoding)
+			if encoding == "gzip" && status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			var body io.Reader = r.Body
+			if encoding == "gzip" {
+				zr, err := gzip.NewReader(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				body = zr
+			}
+			bs, _ := io.ReadAll(body)
+			bodies = append(bodies, string(bs))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+		}
+	}
+	var mux http.ServeMux
+	mux.Handle("POST /accept/open-apis/bot/v2/hook/{token}", handler(http.StatusOK))
+	mux.Handle("POST /reject/open-apis/bot/v2/hook/{token}", handler(http.StatusUnsupportedMediaType))
+	mux.Handle("POST /invalid/open-apis/bot/v2/hook/{token}", handler(http.StatusBadRequest))
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	large := strings.Repeat("a", 2*compressThreshold)
+	testCases := []struct {
+		name      string   // 测试项目
+		baseURL   string   // 基础地址
+		msg       string   // 信息内容
+		encodings []string // 预期每次请求的 Content-Encoding
+		fail      bool     // 预期发送失败
+	}{
+		{name: "small", baseURL: s.URL + "/accept", msg: "测试", encodings: []string{""}},
+		{name: "large", baseURL: s.URL + "/accept", msg: large, encodings: []string{"gzip"}},
+		{name: "fallback", baseURL: s.URL + "/reject", msg: large, encodings: []string{"gzip", ""}},
+		{name: "bad request", baseURL: s.URL + "/invalid", msg: large, encodings: []string{"gzip"}, fail: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encodings, bodies = nil, nil
+			client := BotClient{
+				Client:   s.Client(),
+				Logger:   logger,
+				BaseURL:  tc.baseURL,
+				Token:    "85d09ddb-5937-46e7-8628-d7959a93e3af",
+				Compress: true,
+			}
+			err := client.SendText(context.Background(), tc.msg)
+			if !slices.Equal(encodings, tc.encodings) {
+				t.Fatalf("expect encodings %q, got %q", tc.encodings, encodings)
+			}
+			if tc.fail {
+				if err == nil {
+					t.Fatal("expect error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+			if len(bodies) != 1 || !strings.Contains(bodies[0], tc.msg) {
+				t.Fatalf("unexpected bodies: %d", len(bodies))
+			}
+		})
+	}
+}
+
+func TestBotClient_SuccessFunc(t *testing.T) {
+	// 网关将原始响应包装在 result 字段中，并以 ok 字段表示成功
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		if r.Header.Get("X-Fail") != "" {
+			w.Write([]byte(`{"ok":false,"result":null}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+
+	errGateway := errors.New("gateway error")
+	success := func(status int, body []byte) error {
+		var envelope struct {
+			OK bool `json:"ok"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil || !envelope.OK {
+			return errGateway
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name    string                              // 测试项目
+		fail    bool                                // 是否让网关返回失败
+		success func(status int, body []byte) error // 响应检查函数
+		err     error                               // 预期错误
+	}{
+		{name: "custom success", fail: false, success: success, err: nil},
+		{name: "custom failure", fail: true, success: success, err: errGateway},
+		{name: "default", fail: false, success: nil, err: ErrUnexpectedContentType},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.SuccessFunc = tc.success
+			if tc.fail {
+				client.Headers = http.Header{"X-Fail": {"1"}}
+			}
+			err := client.SendText(context.Background(), "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendFile(t *testing.T) {
+	var got []byte
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	testCases := []struct {
+		name   string       // 测试项目
+		send   func() error // 发送函数
+		expect string       // 预期请求体
+		err    error        // 预期错误
+	}{
+		{
+			name:   "file",
+			send:   func() error { return client.SendFile(context.Background(), "file_v2_xxx") },
+			expect: `{"msg_type":"file","content":{"file_key":"file_v2_xxx"}}`,
+		},
+		{
+			name:   "audio",
+			send:   func() error { return client.SendAudio(context.Background(), "file_v2_xxx") },
+			expect: `{"msg_type":"audio","content":{"file_key":"file_v2_xxx"}}`,
+		},
+		{
+			name:   "media",
+			send:   func() error { return client.SendMedia(context.Background(), "file_v2_xxx", "img_v2_xxx") },
+			expect: `{"msg_type":"media","content":{"file_key":"file_v2_xxx","image_key":"img_v2_xxx"}}`,
+		},
+		{
+			name: "empty file key",
+			send: func() error { return client.SendFile(context.Background(), "") },
+			err:  ErrEmptyID,
+		},
+		{
+			name: "empty image key",
+			send: func() error { return client.SendMedia(context.Background(), "file_v2_xxx", "") },
+			err:  ErrEmptyID,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := tc.send()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if string(got) != tc.expect {
+				t.Fatalf("expect %s, got %s", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_AcceptLanguage(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		headers        http.Header
+		expect         string
+	}{
+		{name: "unset", expect: ""},
+		{name: "set", acceptLanguage: "en-US", expect: "en-US"},
+		{name: "headers", headers: http.Header{"Accept-Language": {"ja-JP"}}, expect: "ja-JP"},
+		{name: "override headers", acceptLanguage: "en-US", headers: http.Header{"Accept-Language": {"ja-JP"}}, expect: "en-US"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Accept-Language")
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+			}))
+			t.Cleanup(s.Close)
+
+			client := BotClient{
+				Client:         s.Client(),
+				Logger:         logger,
+				BaseURL:        s.URL,
+				Token:          "85d09ddb-5937-46e7-8628-d7959a93e3af",
+				Headers:        tt.headers,
+				AcceptLanguage: tt.acceptLanguage,
+			}
+			err := client.SendText(context.Background(), "测试")
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+			if got != tt.expect {
+				t.Fatalf("expect Accept-Language %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestContextWithToken(t *testing.T) {
+	var got string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		got = r.PathValue("token")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	tests := []struct {
+		name   string
+		token  string          // 客户端的令牌
+		ctx    context.Context // ctx 对象
+		expect string          // 预期使用的令牌
+		err    error           // 预期错误
+	}{
+		{name: "field", token: "field", ctx: context.Background(), expect: "field"},
+		{name: "override", token: "field", ctx: ContextWithToken(context.Background(), "tenant"), expect: "tenant"},
+		{name: "context only", ctx: ContextWithToken(context.Background(), "tenant"), expect: "tenant"},
+		{name: "empty override", token: "field", ctx: ContextWithToken(context.Background(), ""), expect: "field"},
+		{name: "none", ctx: context.Background(), err: ErrNeedToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = ""
+			client := base
+			client.Token = tt.token
+			err := client.SendText(tt.ctx, "测试")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expect %v, got %v", tt.err, err)
+			}
+			if got != tt.expect {
+				t.Fatalf("expect token %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_PreserveResponseBody(t *testing.T) {
+	const (
+		apiBody  = `{"code":19001,"data":{},"msg":"param invalid"}`
+		htmlBody = `<html>gateway</html>`
+	)
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("token") {
+		case "api":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(apiBody))
+		case "html":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(htmlBody))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+		}
+	})
+	base, _ := newTestServer(t, &mux)
+
+	tests := []struct {
+		name     string
+		token    string // 令牌，决定响应内容
+		preserve bool   // 是否保留响应内容
+		body     string // 预期的响应内容，为空表示不返回 HTTPError
+		err      error  // 预期包含的原错误
+	}{
+		{name: "api off", token: "api", err: APIError{Code: CodeParamInvalid, Msg: "param invalid"}},
+		{name: "api on", token: "api", preserve: true, body: apiBody, err: APIError{Code: CodeParamInvalid, Msg: "param invalid"}},
+		{name: "content type off", token: "html", err: ErrUnexpectedContentType},
+		{name: "content type on", token: "html", preserve: true, body: htmlBody, err: ErrUnexpectedContentType},
+		{name: "success on", token: "ok", preserve: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := base
+			client.Token = tt.token
+			client.PreserveResponseBody = tt.preserve
+			err := client.SendText(context.Background(), "测试")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expect %v, got %v", tt.err, err)
+			}
+
+			var httpErr HTTPError
+			ok := errors.As(err, &httpErr)
+			if ok != (tt.body != "") {
+				t.Fatalf("expect HTTPError %t, got %v", tt.body != "", err)
+			}
+			if ok && string(httpErr.Body) != tt.body {
+				t.Fatalf("expect body %q, got %q", tt.body, httpErr.Body)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendTextTimeout(t *testing.T) {
+	done := make(chan struct{})
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Slow") != "" {
+			<-done
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+	t.Cleanup(func() { close(done) })
+
+	err := client.SendTextTimeout(context.Background(), time.Second, "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	client.Headers = http.Header{"X-Slow": {"1"}}
+	err = client.SendTextTimeout(context.Background(), time.Millisecond, "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// ctx 的截止时间更早时以 ctx 为准
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = client.SendTextTimeout(ctx, time.Hour, "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expect parent deadline, waited %v", elapsed)
+	}
+}
+
+// 以函数实现的 Doer
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBotClient_Doer(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	var got *http.Request
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		got = req
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"code":0,"data":{},"msg":"success"}`)),
+		}, nil
+	})
+
+	client := BotClient{
+		Doer:    doer,
+		Client:  &http.Client{Transport: doerTransport{}},
+		Logger:  logger,
+		BaseURL: "http://bot.example.com",
+		Token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if got == nil || got.URL.Host != "bot.example.com" {
+		t.Fatalf("expect request to bot.example.com, got %v", got)
+	}
+
+	client.ProxyURL = "http://proxy.example.com"
+	client.Client = nil
+	err = client.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrProxyConflict) {
+		t.Fatalf("expect %v, got %v", ErrProxyConflict, err)
+	}
+}
+
+// 总是失败的传输层，用于确认 Doer 优先于 Client 使用
+type doerTransport struct{}
+
+func (doerTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("unexpected round trip")
+}
+
+func TestBotClient_EscapeText(t *testing.T) {
+	var got string
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Content TextMessage `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+		got = msg.Content.Text
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	const msg = `a < b && <at user_id="all"></at>`
+	testCases := []struct {
+		name     string // 测试项目
+		escape   bool   // 是否转义
+		mentions bool   // 是否使用 SendTextWithMentions
+		expect   string // 预期的文本内容
+	}{
+		{name: "raw", escape: false, expect: msg},
+		{name: "escape", escape: true, expect: `a &lt; b &amp;&amp; &lt;at user_id="all"&gt;&lt;/at&gt;`},
+		{
+			name:     "escape keeps mention tags",
+			escape:   true,
+			mentions: true,
+			expect:   `<at user_id="ou_xxx"></at> a &lt; b &amp;&amp; &lt;at user_id="all"&gt;&lt;/at&gt;`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.EscapeText = tc.escape
+			var err error
+			if tc.mentions {
+				err = client.SendTextWithMentions(context.Background(), msg, []string{"ou_xxx"})
+			} else {
+				err = client.SendText(context.Background(), msg)
+			}
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+			if got != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_OnError(t *testing.T) {
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	var got []Message
+	var gotErr error
+	client.OnError = func(ctx context.Context, msg Message, err error) {
+		got = append(got, msg)
+		gotErr = err
+	}
+
+	err := client.Send(context.Background(), Message{
+		MsgType: MessageTypeText,
+		Content: TextMessage{Text: "测试"},
+	})
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status error, got %v", err)
+	}
+	if len(got) != 1 || got[0].MsgType != MessageTypeText || got[0].Content != (TextMessage{Text: "测试"}) {
+		t.Fatalf("unexpected messages: %+v", got)
+	}
+	if !errors.As(gotErr, &httpErr) {
+		t.Fatalf("expect %v, got %v", err, gotErr)
+	}
+}
+
+func TestBotClient_SendAll(t *testing.T) {
+	var got []string
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Content TextMessage `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+		text := msg.Content.Text
+		got = append(got, text)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if text == "fail" {
+			w.Write([]byte(`{"code":19001,"data":{},"msg":"param invalid"}`))
+			return
+		}
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	messages := func(texts ...string) []Message {
+		var msgs []Message
+		for _, text := range texts {
+			msgs = append(msgs, Message{MsgType: MessageTypeText, Content: TextMessage{Text: text}})
+		}
+		return msgs
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name   string          // 测试项目
+		ctx    context.Context // ctx 对象
+		msgs   []Message       // 信息
+		expect []string        // 预期依次收到的信息
+		err    error           // 预期错误
+	}{
+		{name: "empty", ctx: context.Background(), msgs: nil, expect: nil},
+		{name: "ordered", ctx: context.Background(), msgs: messages("1", "2", "3"), expect: []string{"1", "2", "3"}},
+		{
+			name:   "stop at first error",
+			ctx:    context.Background(),
+			msgs:   messages("1", "fail", "3"),
+			expect: []string{"1", "fail"},
+			err:    errmatch.Contains("第 2 条信息发送失败 (共 3 条)"),
+		},
+		{name: "canceled", ctx: canceled, msgs: messages("1"), expect: nil, err: context.Canceled},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := client.SendAll(tc.ctx, tc.msgs)
+			if !errors.Is(tc.err, err) && !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if !slices.Equal(got, tc.expect) {
+				t.Fatalf("expect %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBuildURL(t *testing.T) {
+	testCases := []struct {
+		name    string // 测试项目
+		base    string // 基础地址
+		version string // 接口版本
+		token   string // 令牌
+		expect  string // 预期地址
+		err     bool   // 是否预期错误
+	}{
+		{name: "default", token: "t", expect: "https://open.feishu.cn/open-apis/bot/v2/hook/t"},
+		{name: "trailing slash", base: "http://localhost/", token: "t", expect: "http://localhost/open-apis/bot/v2/hook/t"},
+		{name: "base path", base: "http://localhost/proxy/", token: "t", expect: "http://localhost/proxy/open-apis/bot/v2/hook/t"},
+		{name: "version", base: "http://localhost", version: "v3", token: "t", expect: "http://localhost/open-apis/bot/v3/hook/t"},
+		{name: "special token", token: "a b?c", expect: "https://open.feishu.cn/open-apis/bot/v2/hook/a%20b%3Fc"},
+		{name: "malformed base", base: "://localhost", token: "t", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := buildURL(tc.base, tc.version, tc.token)
+			if (err != nil) != tc.err {
+				t.Fatalf("expect error %t, got %v", tc.err, err)
+			}
+			if got != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_MaxResponseBytes(t *testing.T) {
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.Header.Get("X-Huge") != "" {
+			w.Write([]byte(`{"padding":"` + strings.Repeat("a", 2<<20) + `"}`))
+			return
+		}
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	testCases := []struct {
+		name    string      // 测试项目
+		limit   int64       // 响应大小上限
+		headers http.Header // 请求头
+		err     error       // 预期错误
+	}{
+		{name: "normal", limit: 0, err: nil},
+		{name: "default limit", limit: 0, headers: http.Header{"X-Huge": {"1"}}, err: ErrResponseTooLarge},
+		{name: "custom limit", limit: 8, err: ErrResponseTooLarge},
+		{name: "raised limit", limit: 4 << 20, headers: http.Header{"X-Huge": {"1"}}, err: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.Headers = tc.headers
+			client.MaxResponseBytes = tc.limit
+			err := client.SendText(context.Background(), "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestContextWithTimestamp(t *testing.T) {
+	var got Message
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	client.Secret = "demo"
+	ctx := ContextWithTimestamp(context.Background(), time.Unix(1599360473, 0))
+	err := client.SendText(ctx, "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if got.Timestamp != "1599360473" {
+		t.Fatalf("expect timestamp %s, got %s", "1599360473", got.Timestamp)
+	}
+	expect := "l1N0gAcBjdwBvGm1xMjOF0XSyaLRpR7tuO5dHfhAYc8="
+	if got.Sign != expect {
+		t.Fatalf("expect sign %s, got %s", expect, got.Sign)
+	}
+
+	if _, ok := TimestampFromContext(ContextWithTimestamp(context.Background(), time.Time{})); ok {
+		t.Fatal("expect zero timestamp ignored")
+	}
+}
+
+func TestBotClient_RedactRequestError(t *testing.T) {
+	var buf bytes.Buffer
+	client := BotClient{
+		Transport: doerTransport{},
+		Logger:    slog.New(slog.NewTextHandler(&buf, nil)),
+		BaseURL:   "http://bot.example.com",
+		Token:     "85d09ddb-5937-46e7-8628-d7959a93e3af",
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err == nil {
+		t.Fatal("expect error, got nil")
+	}
+	var ue *url.Error
+	if !errors.As(err, &ue) {
+		t.Fatalf("expect *url.Error, got %T", err)
+	}
+	if strings.Contains(err.Error(), "85d09ddb-5937-46e7-8628-d7959a93e3af") {
+		t.Fatalf("token should be redacted: %v", err)
+	}
+	if logs := buf.String(); strings.Contains(logs, "85d09ddb-5937-46e7-8628-d7959a93e3af") {
+		t.Fatalf("token should be redacted: %s", logs)
+	}
 }
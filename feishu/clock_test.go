@@ -0,0 +1,64 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBotClient_Clock(t *testing.T) {
+	// 前两次请求返回限流
+	var n int
+	var timestamps, signs []string
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		var msg Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		timestamps = append(timestamps, msg.Timestamp)
+		signs = append(signs, msg.Sign)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if n <= 2 {
+			w.Write([]byte(`{"code":11232,"data":{},"msg":"frequency limited"}`))
+			return
+		}
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	}))
+
+	now := time.Unix(1599360473, 0)
+	var delays []time.Duration
+	client.Secret = "demo"
+	client.Retry = RetryConfig{MaxAttempts: 3, BaseDelay: time.Minute}
+	client.Tracker = new(SendTracker)
+	client.clock = clock{
+		now: func() time.Time { return now },
+		sleep: func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}
+
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if expect := []time.Duration{time.Minute, 2 * time.Minute}; !slices.Equal(delays, expect) {
+		t.Fatalf("expect delays %v, got %v", expect, delays)
+	}
+	if expect := []string{"1599360473", "1599360473", "1599360473"}; !slices.Equal(timestamps, expect) {
+		t.Fatalf("expect timestamps %v, got %v", expect, timestamps)
+	}
+	expect := sign("1599360473", "demo")
+	for _, got := range signs {
+		if got != expect {
+			t.Fatalf("expect sign %q, got %q", expect, got)
+		}
+	}
+	if got := client.LastSentAt(); !got.Equal(now) {
+		t.Fatalf("expect last sent at %v, got %v", now, got)
+	}
+}
@@ -0,0 +1,55 @@
+package feishu
+
+import (
+	"context"
+	"time"
+)
+
+// ctx 中保存令牌使用的键
+type tokenContextKey struct{}
+
+// 函数返回保存了令牌的 ctx。
+// 使用该 ctx 发送信息时，ctx 中的令牌优先于客户端的 Token 字段，
+// 可用于多租户场景下由同一个客户端为不同租户发送信息。token 为空时不覆盖。
+// 签名密钥仍使用客户端的 Secret 字段。
+func ContextWithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// 函数返回 ctx 中保存的令牌。未保存或令牌为空时 ok 为 false。
+func TokenFromContext(ctx context.Context) (token string, ok bool) {
+	token, _ = ctx.Value(tokenContextKey{}).(string)
+	return token, token != ""
+}
+
+// 方法返回使用 ctx 中的令牌覆盖 Token 字段后的客户端。
+func (c BotClient) withContextToken(ctx context.Context) BotClient {
+	if token, ok := TokenFromContext(ctx); ok {
+		c.Token = token
+	}
+	return c
+}
+
+// ctx 中保存签名时间戳使用的键
+type timestampContextKey struct{}
+
+// 函数返回保存了签名时间戳的 ctx。
+// 使用该 ctx 发送信息时，签名与请求中的 timestamp 字段都使用 t，而不是当前时间，
+// 可用于生成可复现的签名，或在本机时钟有偏差时手动校正。t 为零值时不覆盖。
+func ContextWithTimestamp(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, timestampContextKey{}, t)
+}
+
+// 函数返回 ctx 中保存的签名时间戳。未保存或为零值时 ok 为 false。
+func TimestampFromContext(ctx context.Context) (t time.Time, ok bool) {
+	t, _ = ctx.Value(timestampContextKey{}).(time.Time)
+	return t, !t.IsZero()
+}
+
+// 方法返回签名使用的时间。ctx 中保存的时间戳优先，否则为当前时间。
+func (c BotClient) signTime(ctx context.Context) time.Time {
+	if t, ok := TimestampFromContext(ctx); ok {
+		return t
+	}
+	return c.now()
+}
@@ -0,0 +1,30 @@
+package feishu
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+var (
+	defaultMu     sync.RWMutex
+	defaultClient BotClient // 包级函数使用的默认客户端
+)
+
+// 设置包级函数使用的底层 http client。传入 nil 时恢复默认值。
+func SetDefaultHTTPClient(client *http.Client) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultClient.Client = client
+}
+
+// 使用默认客户端向 token 对应的机器人发送文本信息。
+// 适用于一次性发送，需要更多配置时请使用 [BotClient]。
+func SendText(ctx context.Context, token, msg string) error {
+	defaultMu.RLock()
+	c := defaultClient
+	defaultMu.RUnlock()
+
+	c.Token = token
+	return c.SendText(ctx, msg)
+}
@@ -0,0 +1,41 @@
+package feishu
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSendText(t *testing.T) {
+	var got string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		got = r.PathValue("token")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	// 将请求转发到测试服务器
+	target, _ := url.Parse(s.URL)
+	SetDefaultHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r.URL.Scheme = target.Scheme
+			r.URL.Host = target.Host
+			return s.Client().Transport.RoundTrip(r)
+		}),
+	})
+	t.Cleanup(func() { SetDefaultHTTPClient(nil) })
+
+	err := SendText(context.Background(), "85d09ddb-5937-46e7-8628-d7959a93e3af", "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if got != "85d09ddb-5937-46e7-8628-d7959a93e3af" {
+		t.Fatalf("expect %q, got %q", "85d09ddb-5937-46e7-8628-d7959a93e3af", got)
+	}
+}
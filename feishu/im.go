@@ -0,0 +1,200 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// 接收者 ID 类型
+type ReceiveIDType = string
+
+const (
+	ReceiveIDTypeOpenID  ReceiveIDType = "open_id"  // 用户的 open_id
+	ReceiveIDTypeUnionID ReceiveIDType = "union_id" // 用户的 union_id
+	ReceiveIDTypeUserID  ReceiveIDType = "user_id"  // 用户的 user_id
+	ReceiveIDTypeEmail   ReceiveIDType = "email"    // 用户的邮箱
+	ReceiveIDTypeChatID  ReceiveIDType = "chat_id"  // 群 ID
+)
+
+// 消息接口响应数据
+type IMMessageData struct {
+	MessageID string `json:"message_id"` // 消息 ID
+	ChatID    string `json:"chat_id"`    // 消息所在的群 ID
+	MsgType   string `json:"msg_type"`   // 消息类型
+}
+
+// 消息接口请求
+type imRequest struct {
+	ReceiveID string      `json:"receive_id"` // 接收者 ID
+	MsgType   MessageType `json:"msg_type"`   // 消息类型
+	Content   string      `json:"content"`    // 消息内容，为 json 序列化后的字符串
+}
+
+// 更新消息卡片接口请求
+type imUpdateRequest struct {
+	Content string `json:"content"` // 卡片内容，为 json 序列化后的字符串
+}
+
+// 飞书消息接口客户端。
+// 通过开放平台的 /open-apis/im/v1/messages 接口，以应用身份向指定的用户或群发送信息。
+// 与 webhook 机器人 [BotClient] 不同，该接口使用 tenant_access_token 鉴权，并需要指定接收者。
+type IMClient struct {
+	// 客户端配置。使用其中的 Client、Logger、BaseURL、TenantAccessToken 等字段，
+	// 不使用 Token、Secret 等 webhook 专用字段。
+	BotClient BotClient
+}
+
+// 方法向指定接收者发送文本信息。
+func (c IMClient) SendText(ctx context.Context, receiveIDType ReceiveIDType, receiveID string, msg string) error {
+	_, err := c.Send(ctx, receiveIDType, receiveID, Message{
+		MsgType: MessageTypeText,
+		Content: TextMessage{Text: c.BotClient.escapeText(msg)},
+	})
+	return err
+}
+
+// 方法向指定接收者发送信息，并返回解析后的响应。
+// 信息内容与 webhook 机器人相同，消息卡片使用 Card 字段。
+// 未设置 TenantAccessToken 时返回 [ErrNeedTenantToken]，receiveID 为空时返回 [ErrEmptyID]。
+func (c IMClient) Send(ctx context.Context, receiveIDType ReceiveIDType, receiveID string, msg Message) (SendResponse[IMMessageData], error) {
+	b := c.BotClient
+	b.logger().InfoContext(ctx, "发送消息", slog.String("msgType", msg.MsgType), slog.String("receiveIDType", receiveIDType))
+
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	if b.TenantAccessToken == "" {
+		b.logger().ErrorContext(ctx, "需要提供应用令牌")
+		return SendResponse[IMMessageData]{}, ErrNeedTenantToken
+	}
+	if receiveID == "" {
+		b.logger().ErrorContext(ctx, "需要提供接收者 ID")
+		return SendResponse[IMMessageData]{}, ErrEmptyID
+	}
+
+	u, err := url.Parse(b.baseURL())
+	if err != nil {
+		b.logger().ErrorContext(ctx, "URL 解析失败", slog.Any("err", err))
+		return SendResponse[IMMessageData]{}, err
+	}
+	u = u.JoinPath("/open-apis/im/v1/messages")
+	q := u.Query()
+	q.Set("receive_id_type", receiveIDType)
+	u.RawQuery = q.Encode()
+
+	content := msg.Content
+	if msg.MsgType == MessageTypeInteractive && msg.Card != nil {
+		content = msg.Card
+	}
+	cs, err := b.marshal(content)
+	if err != nil {
+		b.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
+		return SendResponse[IMMessageData]{}, err
+	}
+	bs, err := b.marshal(imRequest{ReceiveID: receiveID, MsgType: msg.MsgType, Content: string(cs)})
+	if err != nil {
+		b.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
+		return SendResponse[IMMessageData]{}, err
+	}
+
+	b.debugBody(ctx, "请求内容", slog.String("url", u.String()), slog.String("body", string(bs)))
+
+	if b.DryRun {
+		b.logger().InfoContext(ctx, "试运行，跳过发送", slog.String("url", u.String()), slog.String("body", string(bs)))
+		return SendResponse[IMMessageData]{}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(bs))
+	if err != nil {
+		b.logger().ErrorContext(ctx, "请求创建失败", slog.Any("err", err))
+		return SendResponse[IMMessageData]{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+b.TenantAccessToken)
+
+	var data SendResponse[IMMessageData]
+	err = b.do(ctx, req, &data)
+	if err != nil {
+		return SendResponse[IMMessageData]{}, err
+	}
+	if data.Code != 0 {
+		b.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.Code), slog.String("msg", data.Msg))
+		return data, APIError{Code: data.Code, Msg: data.Msg}
+	}
+
+	b.logger().InfoContext(ctx, "消息发送成功", slog.String("messageID", data.Data.MessageID))
+	return data, nil
+}
+
+// 方法更新已发送的消息卡片。
+// 通过 PATCH /open-apis/im/v1/messages/{message_id} 接口，以应用身份将卡片内容整体替换为 card，
+// messageID 为 [IMClient.Send] 返回的 IMMessageData.MessageID。
+//
+// 该接口需要 tenant_access_token，且只能更新本应用发送的卡片，卡片需开启 config.update_multi。
+// webhook 机器人发送的卡片不返回消息 ID，无法通过此方法更新。
+// 未设置 TenantAccessToken 时返回 [ErrNeedTenantToken]，messageID 为空时返回 [ErrEmptyID]。
+func (c IMClient) UpdateCard(ctx context.Context, messageID string, card any) error {
+	b := c.BotClient
+	b.logger().InfoContext(ctx, "更新消息卡片", slog.String("messageID", messageID))
+
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	if b.TenantAccessToken == "" {
+		b.logger().ErrorContext(ctx, "需要提供应用令牌")
+		return ErrNeedTenantToken
+	}
+	if messageID == "" {
+		b.logger().ErrorContext(ctx, "需要提供消息 ID")
+		return ErrEmptyID
+	}
+
+	u, err := url.Parse(b.baseURL())
+	if err != nil {
+		b.logger().ErrorContext(ctx, "URL 解析失败", slog.Any("err", err))
+		return err
+	}
+	u = u.JoinPath("/open-apis/im/v1/messages", messageID)
+
+	cs, err := b.marshal(card)
+	if err != nil {
+		b.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
+		return err
+	}
+	bs, err := b.marshal(imUpdateRequest{Content: string(cs)})
+	if err != nil {
+		b.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
+		return err
+	}
+
+	b.debugBody(ctx, "请求内容", slog.String("url", u.String()), slog.String("body", string(bs)))
+
+	if b.DryRun {
+		b.logger().InfoContext(ctx, "试运行，跳过发送", slog.String("url", u.String()), slog.String("body", string(bs)))
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, u.String(), bytes.NewReader(bs))
+	if err != nil {
+		b.logger().ErrorContext(ctx, "请求创建失败", slog.Any("err", err))
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+b.TenantAccessToken)
+
+	var data SendResponse[struct{}]
+	err = b.do(ctx, req, &data)
+	if err != nil {
+		return err
+	}
+	if data.Code != 0 {
+		b.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.Code), slog.String("msg", data.Msg))
+		return APIError{Code: data.Code, Msg: data.Msg}
+	}
+
+	b.logger().InfoContext(ctx, "消息卡片更新成功")
+	return nil
+}
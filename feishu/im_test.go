@@ -0,0 +1,135 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIMClient_SendText(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	var got imRequest
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/im/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t-xxx" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("receive_id_type") != ReceiveIDTypeChatID {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":0,"data":{"message_id":"om_xxx","chat_id":"oc_xxx","msg_type":"text"},"msg":"success"}`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	testCases := []struct {
+		name      string // 测试项目
+		token     string // 应用令牌
+		receiveID string // 接收者 ID
+		err       error  // 预期错误
+	}{
+		{name: "normal", token: "t-xxx", receiveID: "oc_xxx", err: nil},
+		{name: "need tenant token", token: "", receiveID: "oc_xxx", err: ErrNeedTenantToken},
+		{name: "empty id", token: "t-xxx", receiveID: "", err: ErrEmptyID},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = imRequest{}
+			c := IMClient{BotClient: BotClient{
+				Client:            s.Client(),
+				Logger:            logger,
+				BaseURL:           s.URL,
+				TenantAccessToken: tc.token,
+			}}
+			err := c.SendText(context.Background(), ReceiveIDTypeChatID, tc.receiveID, "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+			expect := imRequest{ReceiveID: "oc_xxx", MsgType: MessageTypeText, Content: `{"text":"测试"}`}
+			if got != expect {
+				t.Fatalf("expect %+v, got %+v", expect, got)
+			}
+		})
+	}
+}
+
+func TestIMClient_UpdateCard(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	var got imUpdateRequest
+	var mux http.ServeMux
+	mux.HandleFunc("PATCH /open-apis/im/v1/messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t-xxx" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.PathValue("id") != "om_xxx" {
+			w.Write([]byte(`{"code":230001,"msg":"message not found"}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"code":0,"data":{},"msg":"success"}`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	card := map[string]any{"config": map[string]any{"update_multi": true}}
+	testCases := []struct {
+		name      string // 测试项目
+		token     string // 应用令牌
+		messageID string // 消息 ID
+		err       error  // 预期错误
+	}{
+		{name: "normal", token: "t-xxx", messageID: "om_xxx", err: nil},
+		{name: "need tenant token", token: "", messageID: "om_xxx", err: ErrNeedTenantToken},
+		{name: "empty id", token: "t-xxx", messageID: "", err: ErrEmptyID},
+		{name: "not found", token: "t-xxx", messageID: "om_yyy", err: APIError{Code: 230001, Msg: "message not found"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = imUpdateRequest{}
+			c := IMClient{BotClient: BotClient{
+				Client:            s.Client(),
+				Logger:            logger,
+				BaseURL:           s.URL,
+				TenantAccessToken: tc.token,
+			}}
+			err := c.UpdateCard(context.Background(), tc.messageID, card)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+			expect := imUpdateRequest{Content: `{"config":{"update_multi":true}}`}
+			if got != expect {
+				t.Fatalf("expect %+v, got %+v", expect, got)
+			}
+		})
+	}
+}
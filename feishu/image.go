@@ -0,0 +1,97 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// 图片大小上限
+const maxImageSize = 10 << 20
+
+// 上传图片响应数据
+type UploadImageData struct {
+	ImageKey string `json:"image_key"` // 图片的 key
+}
+
+// 上传图片，返回 image_key。
+// 该接口属于飞书开放平台接口，需要使用应用的 tenant_access_token 鉴权，
+// 因此必须设置 [BotClient.TenantAccessToken]，否则返回 [ErrNeedTenantToken]。
+// 仅持有 webhook 令牌的自定义机器人无法上传图片，只能发送已上传图片的 image_key。
+// 图片大小不能超过 10MB，超过时返回 [ErrImageTooLarge]。
+func (c BotClient) UploadImage(ctx context.Context, r io.Reader) (string, error) {
+	c.logger().InfoContext(ctx, "上传图片")
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if c.TenantAccessToken == "" {
+		c.logger().ErrorContext(ctx, "需要提供应用令牌")
+		return "", ErrNeedTenantToken
+	}
+
+	u, err := url.Parse(c.baseURL())
+	if err != nil {
+		c.logger().ErrorContext(ctx, "URL 解析失败", slog.Any("err", err))
+		return "", err
+	}
+	u = u.JoinPath("/open-apis/im/v1/images")
+
+	content, err := io.ReadAll(io.LimitReader(r, maxImageSize+1))
+	if err != nil {
+		c.logger().ErrorContext(ctx, "图片读取失败", slog.Any("err", err))
+		return "", err
+	}
+	if len(content) > maxImageSize {
+		c.logger().ErrorContext(ctx, "图片过大")
+		return "", ErrImageTooLarge
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	err = mw.WriteField("image_type", "message")
+	if err != nil {
+		c.logger().ErrorContext(ctx, "表单写入失败", slog.Any("err", err))
+		return "", err
+	}
+	fw, err := mw.CreateFormFile("image", "image")
+	if err != nil {
+		c.logger().ErrorContext(ctx, "表单创建失败", slog.Any("err", err))
+		return "", err
+	}
+	_, err = fw.Write(content)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "表单写入失败", slog.Any("err", err))
+		return "", err
+	}
+	err = mw.Close()
+	if err != nil {
+		c.logger().ErrorContext(ctx, "表单写入失败", slog.Any("err", err))
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), &body)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "请求创建失败", slog.Any("err", err))
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+c.TenantAccessToken)
+
+	var data SendResponse[UploadImageData]
+	err = c.do(ctx, req, &data)
+	if err != nil {
+		return "", err
+	}
+	if data.Code != 0 {
+		c.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.Code), slog.String("msg", data.Msg))
+		return "", APIError{Code: data.Code, Msg: data.Msg}
+	}
+
+	c.logger().InfoContext(ctx, "图片上传成功", slog.String("imageKey", data.Data.ImageKey))
+	return data.Data.ImageKey, nil
+}
@@ -0,0 +1,82 @@
+package feishu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kvii/bot/internal/errmatch"
+)
+
+func TestBotClient_UploadImage(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/im/v1/images", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if r.Header.Get("Authorization") != "Bearer t-g1044ghJRUIJJ5ELPPP6MQ" {
+			w.Write([]byte(`{ "code": 99991663, "msg": "Invalid access token for authorization.", "data": {} }`))
+			return
+		}
+		if r.FormValue("image_type") != "message" {
+			w.Write([]byte(`{ "code": 234001, "msg": "Invalid request param.", "data": {} }`))
+			return
+		}
+		w.Write([]byte(`{ "code": 0, "msg": "success", "data": { "image_key": "img_v2_xxx" } }`))
+	})
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name     string // 测试项目
+		token    string // 应用令牌
+		imageKey string // 预期 image_key
+		err      error  // 预期错误
+	}{
+		{
+			name:     "normal",
+			token:    "t-g1044ghJRUIJJ5ELPPP6MQ",
+			imageKey: "img_v2_xxx",
+			err:      nil,
+		},
+		{
+			name:     "empty token",
+			token:    "",
+			imageKey: "",
+			err:      ErrNeedTenantToken,
+		},
+		{
+			name:     "invalid token",
+			token:    "invalid",
+			imageKey: "",
+			err:      errmatch.Contains("Invalid access token"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.TenantAccessToken = tc.token
+			imageKey, err := client.UploadImage(context.Background(), strings.NewReader("image"))
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if imageKey != tc.imageKey {
+				t.Fatalf("expect %q, got %q", tc.imageKey, imageKey)
+			}
+			if imageKey == "" {
+				return
+			}
+
+			err = client.SendImage(context.Background(), imageKey)
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+		})
+	}
+}
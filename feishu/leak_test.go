@@ -0,0 +1,107 @@
+package feishu
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBotClient_CancelNoLeak(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 处理函数阻塞到客户端断开连接
+	started := make(chan struct{}, 1)
+	var active atomic.Int64
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 读完请求体后服务端才能感知连接断开
+		io.Copy(io.Discard, r.Body)
+		started <- struct{}{}
+		<-r.Context().Done()
+	}))
+	s.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			active.Add(1)
+		case http.StateClosed, http.StateHijacked:
+			active.Add(-1)
+		}
+	}
+	s.Start()
+	t.Cleanup(s.Close)
+
+	transport := &http.Transport{}
+	t.Cleanup(transport.CloseIdleConnections)
+	client := BotClient{
+		Client:  &http.Client{Transport: transport},
+		Logger:  logger,
+		BaseURL: s.URL,
+		Token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+	}
+
+	before := runtime.NumGoroutine()
+	for range 5 {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-started
+			cancel()
+		}()
+		err := client.SendText(ctx, "测试")
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expect %v, got %v", context.Canceled, err)
+		}
+	}
+	transport.CloseIdleConnections()
+
+	// 连接与 goroutine 需要在一段时间内全部释放
+	deadline := time.Now().Add(2 * time.Second)
+	for active.Load() != 0 || runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("leak: %d connections open, goroutines %d -> %d", active.Load(), before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// 记录响应体是否关闭的 io.ReadCloser
+type closeTracker struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestBotClient_DoerErrorClosesBody(t *testing.T) {
+	body := &closeTracker{Reader: strings.NewReader("{}")}
+	client := BotClient{
+		Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusFound, Body: body}, errors.New("redirect failed")
+		}),
+		Logger:  NopLogger(),
+		BaseURL: "http://bot.example.com",
+		Token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err == nil {
+		t.Fatal("expect error, got nil")
+	}
+	if !body.closed.Load() {
+		t.Fatal("expect response body closed")
+	}
+}
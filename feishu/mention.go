@@ -0,0 +1,48 @@
+package feishu
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// @ 标签中表示所有人的用户 ID
+const MentionAll = "all"
+
+// 返回 @ 指定用户的文本标签，用于文本信息内容中。
+//
+// 自定义机器人的 @ 标签使用用户的 open_id (ou_ 开头)，它在每个应用下各不相同；
+// user_id 是用户在租户内的唯一标识，需要应用权限才能获取，自定义机器人一般只能拿到 open_id。
+func AtUser(openID string) string {
+	return fmt.Sprintf(`<at user_id="%s"></at>`, openID)
+}
+
+// 返回 @ 所有人的文本标签，用于文本信息内容中。
+// 群需要开启 @ 所有人的权限。
+func AtAll() string {
+	return AtUser(MentionAll)
+}
+
+// 发送文本信息，并在信息开头 @ 指定的用户。
+// openIDs 为用户的 open_id 列表，[MentionAll] 表示所有人。
+func (c BotClient) SendTextWithMentions(ctx context.Context, msg string, openIDs []string) error {
+	c.logger().InfoContext(ctx, "发送文本消息", slog.Int("mentioned", len(openIDs)))
+
+	var b strings.Builder
+	for _, id := range openIDs {
+		b.WriteString(AtUser(id))
+		b.WriteString(" ")
+	}
+	b.WriteString(c.escapeText(msg))
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeText,
+		Content: TextMessage{Text: b.String()},
+	})
+}
+
+// 发送文本信息，并在信息开头 @ 所有人。
+func (c BotClient) SendTextMentionAll(ctx context.Context, msg string) error {
+	return c.SendTextWithMentions(ctx, msg, []string{MentionAll})
+}
@@ -0,0 +1,44 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestAt(t *testing.T) {
+	if got := AtUser("ou_xxx"); got != `<at user_id="ou_xxx"></at>` {
+		t.Fatalf("unexpected AtUser: %s", got)
+	}
+	if got := AtAll(); got != `<at user_id="all"></at>` {
+		t.Fatalf("unexpected AtAll: %s", got)
+	}
+}
+
+func TestBotClient_SendTextWithMentions(t *testing.T) {
+	var got string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /open-apis/bot/v2/hook/{token}", func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Content TextMessage `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+		got = msg.Content.Text
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{ "StatusCode": 0, "StatusMessage": "success", "code": 0, "data": {}, "msg": "success" }`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendTextWithMentions(context.Background(), "测试", []string{"ou_a", "all"})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	expect := `<at user_id="ou_a"></at> <at user_id="all"></at> 测试`
+	if got != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
@@ -0,0 +1,38 @@
+package feishu
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// webhook 地址的路径前缀
+const hookPathPrefix = "/open-apis/bot/v2/hook/"
+
+// 函数解析完整的 webhook 地址，如 https://open.feishu.cn/open-apis/bot/v2/hook/xxx。
+// 返回的 baseURL 为协议与主机部分，token 为路径的最后一段。
+// 路径不符合 /open-apis/bot/v2/hook/<token> 格式时返回错误。
+func ParseWebhook(fullURL string) (baseURL, token string, err error) {
+	u, err := url.Parse(fullURL)
+	if err != nil {
+		return "", "", fmt.Errorf("webhook 地址错误: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", "", fmt.Errorf("webhook 地址错误: %q 缺少协议或主机", fullURL)
+	}
+
+	token, ok := strings.CutPrefix(u.Path, hookPathPrefix)
+	if !ok || token == "" || strings.Contains(token, "/") {
+		return "", "", fmt.Errorf("webhook 地址错误: 路径 %q 需要为 %s<token> 格式", u.Path, hookPathPrefix)
+	}
+	return u.Scheme + "://" + u.Host, token, nil
+}
+
+// 函数根据完整的 webhook 地址创建客户端，见 [ParseWebhook]。
+func NewBotClientFromURL(fullURL string) (BotClient, error) {
+	baseURL, token, err := ParseWebhook(fullURL)
+	if err != nil {
+		return BotClient{}, err
+	}
+	return BotClient{BaseURL: baseURL, Token: token}, nil
+}
@@ -0,0 +1,67 @@
+package feishu
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kvii/bot/internal/errmatch"
+)
+
+func TestParseWebhook(t *testing.T) {
+	testCases := []struct {
+		name    string // 测试项目
+		fullURL string // 完整地址
+		baseURL string // 预期基础地址
+		token   string // 预期令牌
+		err     error  // 预期错误
+	}{
+		{
+			name:    "normal",
+			fullURL: "https://open.feishu.cn/open-apis/bot/v2/hook/85d09ddb-5937-46e7-8628-d7959a93e3af",
+			baseURL: "https://open.feishu.cn",
+			token:   "85d09ddb-5937-46e7-8628-d7959a93e3af",
+		},
+		{
+			name:    "lark",
+			fullURL: "https://open.larksuite.com/open-apis/bot/v2/hook/xxx",
+			baseURL: "https://open.larksuite.com",
+			token:   "xxx",
+		},
+		{
+			name:    "token only",
+			fullURL: "85d09ddb-5937-46e7-8628-d7959a93e3af",
+			err:     errmatch.Contains("缺少协议或主机"),
+		},
+		{
+			name:    "wrong path",
+			fullURL: "https://open.feishu.cn/open-apis/bot/hook/xxx",
+			err:     errmatch.Contains("webhook 地址错误"),
+		},
+		{
+			name:    "empty token",
+			fullURL: "https://open.feishu.cn/open-apis/bot/v2/hook/",
+			err:     errmatch.Contains("webhook 地址错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			baseURL, token, err := ParseWebhook(tc.fullURL)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if baseURL != tc.baseURL || token != tc.token {
+				t.Fatalf("expect %q %q, got %q %q", tc.baseURL, tc.token, baseURL, token)
+			}
+		})
+	}
+}
+
+func TestNewBotClientFromURL(t *testing.T) {
+	c, err := NewBotClientFromURL("https://open.feishu.cn/open-apis/bot/v2/hook/xxx")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if c.BaseURL != "https://open.feishu.cn" || c.Token != "xxx" {
+		t.Fatalf("unexpected client: %+v", c)
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/kvii/bot
 
 go 1.22.3
+
+require golang.org/x/time v0.10.0
@@ -0,0 +1,40 @@
+// backoff 包提供各平台客户端共用的指数退避重试逻辑。
+package backoff
+
+import (
+	"context"
+	"time"
+)
+
+// 单次等待时间上限
+const MaxDelay = 5 * time.Minute
+
+// 函数返回第 attempt 次失败后的等待时间。
+// attempt 从 1 开始，等待时间为 base * 2^(attempt-1)，最大不超过 [MaxDelay]。
+// 次数较大时直接返回 [MaxDelay]，不会因移位溢出得到负数或零。
+func Delay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return base
+	}
+	if attempt < 1 {
+		return min(base, MaxDelay)
+	}
+	shift := attempt - 1
+	if shift >= 63 || base > MaxDelay>>shift {
+		return MaxDelay
+	}
+	return base << shift
+}
+
+// 函数等待 d 时长。ctx 先结束时返回 ctx.Err()。
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
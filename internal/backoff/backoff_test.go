@@ -0,0 +1,48 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	testCases := []struct {
+		name    string        // 测试项目
+		base    time.Duration // 基础等待时间
+		attempt int           // 失败次数
+		want    time.Duration // 预期等待时间
+	}{
+		{name: "first", base: time.Second, attempt: 1, want: time.Second},
+		{name: "third", base: time.Second, attempt: 3, want: 4 * time.Second},
+		{name: "zero attempt", base: time.Second, attempt: 0, want: time.Second},
+		{name: "capped", base: time.Second, attempt: 10, want: MaxDelay},
+		{name: "large base", base: time.Hour, attempt: 1, want: MaxDelay},
+		{name: "overflow 63", base: time.Millisecond, attempt: 63, want: MaxDelay},
+		{name: "overflow 64", base: time.Millisecond, attempt: 64, want: MaxDelay},
+		{name: "overflow 65", base: time.Nanosecond, attempt: 65, want: MaxDelay},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Delay(tc.base, tc.attempt)
+			if got != tc.want {
+				t.Fatalf("expect %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSleep(t *testing.T) {
+	err := Sleep(context.Background(), time.Millisecond)
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Sleep(ctx, time.Hour)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,27 @@
+// budget 包提供各平台客户端共用的重试预算。
+package budget
+
+import "golang.org/x/time/rate"
+
+// 重试预算，限制客户端整体的重试频率，避免服务端故障时产生大量重试。
+// 预算为令牌桶：每次重试消耗一个令牌，令牌按固定速率补充；令牌不足时不再重试，
+// 即使 Retry 配置允许更多次尝试。首次发送不消耗令牌。
+// BotClient 以值传递，因此预算以指针形式保存在 RetryBudget 字段中，复制出的客户端共享同一份预算。
+// 可以在多个 goroutine 间并发使用。
+type RetryBudget struct {
+	limiter *rate.Limiter
+}
+
+// 创建重试预算。perSecond 为每秒补充的令牌数，burst 为令牌桶容量，初始时令牌桶是满的。
+func New(perSecond float64, burst int) *RetryBudget {
+	return &RetryBudget{limiter: rate.NewLimiter(rate.Limit(perSecond), burst)}
+}
+
+// 方法尝试消耗一个令牌，返回是否允许重试。预算为 nil 时总是允许。
+// 由客户端在每次重试前调用。
+func (b *RetryBudget) Allow() bool {
+	if b == nil {
+		return true
+	}
+	return b.limiter.Allow()
+}
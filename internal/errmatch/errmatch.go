@@ -0,0 +1,24 @@
+// errmatch 包提供测试中判断错误信息的辅助函数。
+// wx 与 feishu 的测试无法导入依赖它们的 bottest 包，因此共用该包。
+package errmatch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 返回一个用于判断错误信息是否包含指定字符串的错误对象。
+// 需要将其作为 errors.Is 的第一个参数使用。
+func Contains(s string) error {
+	return contains{s}
+}
+
+type contains struct{ string }
+
+func (e contains) Error() string {
+	return fmt.Sprintf("err should contains %q", e.string)
+}
+
+func (e contains) Is(err error) bool {
+	return err != nil && strings.Contains(err.Error(), e.string)
+}
@@ -0,0 +1,35 @@
+// tracker 包提供各平台客户端共用的发送记录。
+package tracker
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// 发送记录，保存最近一次发送成功的时间，可用于自行控制发送频率。
+// BotClient 以值传递，因此记录以指针形式保存在 Tracker 字段中，
+// 复制出的客户端共享同一份记录。可以在多个 goroutine 间并发使用。
+type SendTracker struct {
+	lastSentAt atomic.Int64 // 最近一次发送成功的时间，单位为纳秒
+}
+
+// 方法返回最近一次发送成功的时间。尚未发送成功时返回零值。
+func (t *SendTracker) LastSentAt() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	n := t.lastSentAt.Load()
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// 方法记录一次发送成功。记录为 nil 时不做任何事。
+// 由客户端在发送成功后调用。
+func (t *SendTracker) Record(now time.Time) {
+	if t == nil {
+		return
+	}
+	t.lastSentAt.Store(now.UnixNano())
+}
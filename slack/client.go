@@ -0,0 +1,136 @@
+package slack
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 信息
+type Message struct {
+	Text   string `json:"text,omitempty"`   // 文本内容。使用 blocks 时作为通知的后备文本。
+	Blocks any    `json:"blocks,omitempty"` // Block Kit 布局块
+}
+
+// 接口错误。响应不为 ok 时返回。
+// Slack 在失败时返回非 200 状态码以及纯文本的错误说明，如 invalid_payload。
+type APIError struct {
+	StatusCode int    // 响应状态码
+	Message    string // 错误说明
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("响应异常: %d %s", e.StatusCode, e.Message)
+}
+
+// 预定义错误
+var (
+	ErrNeedWebhookURL = errors.New("slack: need webhook url") // 需要提供 webhook 地址
+)
+
+// Slack incoming webhook 客户端
+type BotClient struct {
+	Client     *http.Client // 底层 http client。不填则使用默认值。
+	Logger     *slog.Logger // 日志 logger。不填则使用默认值。
+	WebhookURL string       // webhook 地址，如 https://hooks.slack.com/services/T000/B000/XXX。地址本身即为令牌，日志与错误中会隐去路径。
+}
+
+// 发送文本信息。
+func (c BotClient) SendText(ctx context.Context, msg string) error {
+	c.logger().InfoContext(ctx, "发送文本消息")
+	return c.send(ctx, Message{Text: msg})
+}
+
+// 发送 Block Kit 信息。
+// blocks 为 Block Kit 布局块数组，可以使用 Block Kit Builder 生成的 json 对象。
+func (c BotClient) SendBlocks(ctx context.Context, blocks any) error {
+	c.logger().InfoContext(ctx, "发送 Block Kit 消息")
+	return c.send(ctx, Message{Blocks: blocks})
+}
+
+// 方法发送信息。
+func (c BotClient) Send(ctx context.Context, msg Message) error {
+	c.logger().InfoContext(ctx, "发送消息")
+	return c.send(ctx, msg)
+}
+
+func (c BotClient) send(ctx context.Context, msg Message) error {
+	if c.WebhookURL == "" {
+		c.logger().ErrorContext(ctx, "需要提供 webhook 地址")
+		return ErrNeedWebhookURL
+	}
+
+	bs, err := json.Marshal(msg)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.WebhookURL, bytes.NewReader(bs))
+	if err != nil {
+		err = redactErr(err)
+		c.logger().ErrorContext(ctx, "请求创建失败", slog.Any("err", err))
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		err = redactErr(err)
+		c.logger().ErrorContext(ctx, "请求发送失败", slog.Any("err", err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	bs, err = io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "响应读取失败", slog.Any("err", err))
+		return err
+	}
+
+	// 与企业微信、飞书不同，Slack 成功时返回纯文本 ok，而不是 json。
+	if body := string(bytes.TrimSpace(bs)); resp.StatusCode != http.StatusOK || body != "ok" {
+		c.logger().ErrorContext(ctx, "响应异常", slog.Int("status-code", resp.StatusCode), slog.String("body", body))
+		return APIError{StatusCode: resp.StatusCode, Message: body}
+	}
+
+	c.logger().InfoContext(ctx, "消息发送成功")
+	return nil
+}
+
+// 函数将请求错误中的 webhook 地址替换为隐去路径后的地址。
+// http.Client 返回的 *url.Error 在错误信息中带有完整的请求地址。
+func redactErr(err error) error {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	redacted := *ue
+	redacted.URL = redact(ue.URL)
+	return &redacted
+}
+
+// 函数隐去 webhook 地址的路径与查询参数，只保留协议与主机，如 https://hooks.slack.com/services/***。
+// 地址无法解析时返回 ***。
+func redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "***"
+	}
+	path := "/***"
+	if strings.HasPrefix(u.Path, "/services/") {
+		path = "/services/***"
+	}
+	return u.Scheme + "://" + u.Host + path
+}
+
+func (c BotClient) logger() *slog.Logger { return cmp.Or(c.Logger, slog.Default()) }
+func (c BotClient) client() *http.Client { return cmp.Or(c.Client, http.DefaultClient) }
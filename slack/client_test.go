@@ -0,0 +1,162 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBotClientSendText(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	var mux http.ServeMux
+	mux.HandleFunc("POST /services/T000/B000/{token}", func(w http.ResponseWriter, r *http.Request) {
+		var msg Message
+		err := json.NewDecoder(r.Body).Decode(&msg)
+
+		w.Header().Set("Content-Type", "text/html")
+		switch {
+		case r.PathValue("token") != "XXX":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("invalid_token"))
+		case err != nil || msg.Text == "" && msg.Blocks == nil:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid_payload"))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("ok"))
+		}
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	testCases := []struct {
+		name   string    // 测试项目
+		client BotClient // 客户端
+		msg    string    // 信息
+		err    error     // 预期错误
+	}{
+		{
+			name: "normal",
+			client: BotClient{
+				Client:     s.Client(),
+				Logger:     logger,
+				WebhookURL: s.URL + "/services/T000/B000/XXX",
+			},
+			msg: "测试",
+			err: nil,
+		},
+		{
+			name: "empty webhook url",
+			client: BotClient{
+				Client: s.Client(),
+				Logger: logger,
+			},
+			msg: "测试",
+			err: ErrNeedWebhookURL,
+		},
+		{
+			name: "invalid token",
+			client: BotClient{
+				Client:     s.Client(),
+				Logger:     logger,
+				WebhookURL: s.URL + "/services/T000/B000/YYY",
+			},
+			msg: "测试",
+			err: APIError{StatusCode: http.StatusForbidden, Message: "invalid_token"},
+		},
+		{
+			name: "invalid payload",
+			client: BotClient{
+				Client:     s.Client(),
+				Logger:     logger,
+				WebhookURL: s.URL + "/services/T000/B000/XXX",
+			},
+			msg: "",
+			err: APIError{StatusCode: http.StatusBadRequest, Message: "invalid_payload"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.client.SendText(context.Background(), tc.msg)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendBlocks(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	var got []byte
+	var mux http.ServeMux
+	mux.HandleFunc("POST /services/T000/B000/XXX", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	client := BotClient{
+		Client:     s.Client(),
+		Logger:     logger,
+		WebhookURL: s.URL + "/services/T000/B000/XXX",
+	}
+	err := client.SendBlocks(context.Background(), []map[string]any{
+		{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": "*测试*"}},
+	})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	expect := `{"blocks":[{"text":{"text":"*测试*","type":"mrkdwn"},"type":"section"}]}`
+	if string(got) != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestBotClient_RedactRequestError(t *testing.T) {
+	// 关闭后的服务端地址无法连接
+	s := httptest.NewServer(http.NotFoundHandler())
+	s.Close()
+
+	var buf bytes.Buffer
+	client := BotClient{
+		Client:     s.Client(),
+		Logger:     slog.New(slog.NewTextHandler(&buf, nil)),
+		WebhookURL: s.URL + "/services/T000/B000/SECRET",
+	}
+	err := client.SendText(context.Background(), "测试")
+	var ue *url.Error
+	if !errors.As(err, &ue) {
+		t.Fatalf("expect *url.Error, got %T", err)
+	}
+	if expect := s.URL + "/services/***"; ue.URL != expect {
+		t.Fatalf("expect url %s, got %s", expect, ue.URL)
+	}
+	for _, out := range []string{err.Error(), buf.String()} {
+		if strings.Contains(out, "SECRET") {
+			t.Fatalf("webhook url should be redacted: %s", out)
+		}
+	}
+}
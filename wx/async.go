@@ -0,0 +1,200 @@
+package wx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// 默认缓冲区大小
+const defaultBufferSize = 64
+
+// 预定义错误
+var (
+	ErrQueueFull   = errors.New("wx: queue full")   // 缓冲区已满，信息被丢弃
+	ErrQueueClosed = errors.New("wx: queue closed") // 异步客户端已关闭
+)
+
+// 异步客户端配置
+type AsyncConfig struct {
+	BufferSize int  // 缓冲区大小。不填则使用默认值 64。
+	DropOnFull bool // 缓冲区满时是否丢弃信息。为 false 时阻塞等待，为 true 时立即返回 [ErrQueueFull]。
+}
+
+// 异步客户端。
+// 信息先进入缓冲区，由后台 goroutine 依次发送，适用于日志告警等不关心发送结果的场景。
+// 后台发送时，缓冲区中连续的、不提醒群成员的文本信息会以换行拼接成一条发送，
+// 拼接后的长度不超过 [MaxTextBytes]。发送失败时只记录日志。
+// 放入信息时读取 ctx 中由 [ContextWithKey] 保存的令牌，后台发送时使用该令牌，令牌不同的信息不会拼接。
+// 使用结束后需要调用 Close 发送剩余信息。
+type AsyncClient struct {
+	client BotClient
+	config AsyncConfig
+
+	mu      sync.RWMutex
+	closed  bool
+	senders sync.WaitGroup // 正在放入信息的调用，全部返回后才关闭缓冲区
+	quit    chan struct{}  // 关闭时关闭，用于唤醒阻塞的调用
+	queue   chan asyncItem
+	done    chan struct{}
+}
+
+// 缓冲区中的信息，以及放入时 ctx 中保存的令牌
+type asyncItem struct {
+	key string  // ctx 中的令牌，为空时使用客户端的 Key
+	msg Message // 信息
+}
+
+// 函数创建异步客户端并启动后台发送。
+func NewAsyncClient(c BotClient, config AsyncConfig) *AsyncClient {
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultBufferSize
+	}
+	a := &AsyncClient{
+		client: c,
+		config: config,
+		quit:   make(chan struct{}),
+		queue:  make(chan asyncItem, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// 方法将文本信息放入缓冲区。
+// 内容为空时直接返回 [ErrEmptyContent]，内容过长时直接返回 [ErrContentTooLong]。
+// 与 [BotClient.SendText] 相同会提醒默认成员，提醒成员的信息不与其他信息拼接。
+func (a *AsyncClient) SendText(ctx context.Context, msg string) error {
+	if err := a.client.checkEmpty(ctx, msg); err != nil {
+		return err
+	}
+	if err := a.client.checkLength(ctx, msg, MaxTextBytes); err != nil {
+		return err
+	}
+	userIDs, mobiles := a.client.defaultMentions(ctx)
+	return a.Send(ctx, Message{
+		MsgType: MessageTypeText,
+		Text: &TextMessage{
+			Content:             msg,
+			MentionedList:       userIDs,
+			MentionedMobileList: mobiles,
+		},
+	})
+}
+
+// 方法将信息放入缓冲区。
+// 缓冲区满时按配置阻塞或返回 [ErrQueueFull]，阻塞时 ctx 结束返回 ctx.Err()。
+// 关闭后返回 [ErrQueueClosed]，阻塞等待时客户端被关闭也返回 [ErrQueueClosed]。
+func (a *AsyncClient) Send(ctx context.Context, msg Message) error {
+	a.mu.RLock()
+	if a.closed {
+		a.mu.RUnlock()
+		return ErrQueueClosed
+	}
+	a.senders.Add(1)
+	a.mu.RUnlock()
+	defer a.senders.Done()
+
+	// 后台发送时没有调用方的 ctx，需要在放入时读取其中的令牌
+	key, _ := KeyFromContext(ctx)
+	item := asyncItem{key: key, msg: msg}
+
+	if a.config.DropOnFull {
+		select {
+		case a.queue <- item:
+			return nil
+		default:
+			a.client.logger().WarnContext(ctx, "缓冲区已满，丢弃信息", slog.String("type", msg.MsgType))
+			return ErrQueueFull
+		}
+	}
+
+	select {
+	case a.queue <- item:
+		return nil
+	case <-a.quit:
+		return ErrQueueClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 方法关闭客户端，并等待缓冲区中的信息发送完毕。
+// ctx 先结束时返回 ctx.Err()，剩余信息仍会在后台继续发送。
+// 重复调用时等待同一次关闭。
+func (a *AsyncClient) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.quit)
+		// 等待正在放入信息的调用返回后再关闭缓冲区，避免向已关闭的通道发送
+		go func() {
+			a.senders.Wait()
+			close(a.queue)
+		}()
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 方法在后台依次发送缓冲区中的信息，直到缓冲区关闭且为空。
+func (a *AsyncClient) run() {
+	defer close(a.done)
+
+	var pending *asyncItem
+	for {
+		var item asyncItem
+		if pending != nil {
+			item, pending = *pending, nil
+		} else {
+			var ok bool
+			item, ok = <-a.queue
+			if !ok {
+				return
+			}
+		}
+
+		msg := item.msg
+		if coalescable(msg) {
+			content := msg.Text.Content
+		merge:
+			for {
+				select {
+				case next, ok := <-a.queue:
+					if !ok {
+						break merge
+					}
+					if next.key != item.key || !coalescable(next.msg) || len(content)+1+len(next.msg.Text.Content) > MaxTextBytes {
+						pending = &next
+						break merge
+					}
+					content += "\n" + next.msg.Text.Content
+				default:
+					break merge
+				}
+			}
+			msg = Message{
+				MsgType: MessageTypeText,
+				Text:    &TextMessage{Content: content},
+			}
+		}
+
+		ctx := ContextWithKey(context.Background(), item.key)
+		if err := a.client.Send(ctx, msg); err != nil {
+			a.client.logger().ErrorContext(ctx, "异步发送失败", slog.Any("err", err))
+		}
+	}
+}
+
+// 函数判断信息能否与其他信息拼接：只有不提醒群成员的文本信息可以拼接。
+func coalescable(msg Message) bool {
+	return msg.MsgType == MessageTypeText && msg.Text != nil &&
+		len(msg.Text.MentionedList) == 0 && len(msg.Text.MentionedMobileList) == 0
+}
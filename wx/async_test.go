@@ -0,0 +1,238 @@
+package wx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncClient(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	testCases := []struct {
+		name     string      // 测试项目
+		config   AsyncConfig // 异步配置
+		mentions []string    // 默认提醒的成员
+		msgs     []string    // 依次放入的信息
+		errs     []error     // 放入每条信息时的预期错误
+		sent     []string    // 预期发送的内容
+	}{
+		{
+			name:   "coalesce",
+			config: AsyncConfig{},
+			msgs:   []string{"a", "b", "c"},
+			errs:   []error{nil, nil, nil},
+			sent:   []string{"a", "b\nc"},
+		},
+		{
+			name:   "drop on full",
+			config: AsyncConfig{BufferSize: 1, DropOnFull: true},
+			msgs:   []string{"a", "b", "c"},
+			errs:   []error{nil, nil, ErrQueueFull},
+			sent:   []string{"a", "b"},
+		},
+		{
+			name:     "default mentions",
+			config:   AsyncConfig{},
+			mentions: []string{"zhangsan"},
+			msgs:     []string{"a", "b", "c"},
+			errs:     []error{nil, nil, nil},
+			sent:     []string{"a", "b", "c"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// 第一个请求阻塞到 release 关闭，使后续信息积压在缓冲区中
+			started := make(chan struct{})
+			release := make(chan struct{})
+			var once sync.Once
+			var mu sync.Mutex
+			var sent []string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var msg Message
+				json.NewDecoder(r.Body).Decode(&msg)
+				mu.Lock()
+				sent = append(sent, msg.Text.Content)
+				mu.Unlock()
+				if !slices.Equal(msg.Text.MentionedList, tc.mentions) {
+					t.Errorf("expect mentions %v, got %v", tc.mentions, msg.Text.MentionedList)
+				}
+
+				once.Do(func() {
+					close(started)
+					<-release
+				})
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+			}))
+			t.Cleanup(s.Close)
+
+			a := NewAsyncClient(BotClient{
+				Client:  s.Client(),
+				Logger:  logger,
+				BaseURL: s.URL,
+				Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+
+				DefaultMentionedList: tc.mentions,
+			}, tc.config)
+
+			for i, msg := range tc.msgs {
+				err := a.SendText(context.Background(), msg)
+				if !errors.Is(err, tc.errs[i]) {
+					t.Fatalf("message %d: expect %v, got %v", i, tc.errs[i], err)
+				}
+				if i == 0 {
+					<-started
+				}
+			}
+			close(release)
+
+			if err := a.Close(context.Background()); err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+			if !slices.Equal(sent, tc.sent) {
+				t.Fatalf("expect %q, got %q", tc.sent, sent)
+			}
+
+			err := a.SendText(context.Background(), "closed")
+			if !errors.Is(err, ErrQueueClosed) {
+				t.Fatalf("expect %v, got %v", ErrQueueClosed, err)
+			}
+		})
+	}
+}
+
+func TestAsyncClient_CloseWhileBlocked(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 请求阻塞到 release 关闭，使缓冲区保持已满
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(started) })
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	t.Cleanup(s.Close)
+
+	a := NewAsyncClient(BotClient{
+		Client:  s.Client(),
+		Logger:  logger,
+		BaseURL: s.URL,
+		Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+	}, AsyncConfig{BufferSize: 1})
+
+	if err := a.SendText(context.Background(), "a"); err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	<-started
+	if err := a.SendText(context.Background(), "b"); err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	// 缓冲区已满，该调用阻塞到客户端关闭
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- a.SendText(context.Background(), "c")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := a.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	select {
+	case err := <-blocked:
+		if !errors.Is(err, ErrQueueClosed) {
+			t.Fatalf("expect %v, got %v", ErrQueueClosed, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked sender not released by Close")
+	}
+
+	close(release)
+	if err := a.Close(context.Background()); err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+}
+
+func TestAsyncClient_ContextKey(t *testing.T) {
+	// 第一个请求阻塞到 release 关闭，使后续信息积压在缓冲区中
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	var mu sync.Mutex
+	var sent []string
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		mu.Lock()
+		sent = append(sent, r.URL.Query().Get("key")+":"+msg.Text.Content)
+		mu.Unlock()
+
+		once.Do(func() {
+			close(started)
+			<-release
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	base.Key = "default"
+	a := NewAsyncClient(base, AsyncConfig{})
+
+	msgs := []struct {
+		key string // ctx 中的令牌
+		msg string // 信息
+	}{
+		{key: "tenant-a", msg: "a"},
+		{key: "tenant-a", msg: "b"},
+		{key: "tenant-a", msg: "c"},
+		{key: "", msg: "d"},
+		{key: "tenant-b", msg: "e"},
+	}
+	for i, m := range msgs {
+		ctx := context.Background()
+		if m.key != "" {
+			ctx = ContextWithKey(ctx, m.key)
+		}
+		if err := a.SendText(ctx, m.msg); err != nil {
+			t.Fatalf("message %d: expect nil, got %v", i, err)
+		}
+		if i == 0 {
+			<-started
+		}
+	}
+	close(release)
+
+	if err := a.Close(context.Background()); err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	expect := []string{"tenant-a:a", "tenant-a:b\nc", "default:d", "tenant-b:e"}
+	if !slices.Equal(sent, expect) {
+		t.Fatalf("expect %q, got %q", expect, sent)
+	}
+}
@@ -0,0 +1,18 @@
+package wx
+
+import (
+	"errors"
+
+	"github.com/kvii/bot/internal/budget"
+)
+
+// 重试预算耗尽时返回，此时返回的错误同时包含最后一次发送的错误。
+var ErrRetryBudgetExhausted = errors.New("wx: retry budget exhausted")
+
+// 重试预算，限制客户端整体的重试频率。实现与其他平台的客户端共用。
+type RetryBudget = budget.RetryBudget
+
+// 创建重试预算。perSecond 为每秒补充的令牌数，burst 为令牌桶容量，初始时令牌桶是满的。
+func NewRetryBudget(perSecond float64, burst int) *RetryBudget {
+	return budget.New(perSecond, burst)
+}
@@ -0,0 +1,86 @@
+package wx
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 函数校验企业微信回调请求的签名。
+// msgSignature 为请求参数 msg_signature 的值，encrypt 为加密的信息内容 (验证回调地址时为 echostr)。
+// 签名为 token、timestamp、nonce 与 encrypt 按字典序排序后拼接，再进行 sha1 计算的十六进制编码。
+// 函数不检查时间戳是否过期。
+func VerifySignature(token, timestamp, nonce, encrypt, msgSignature string) bool {
+	strs := []string{token, timestamp, nonce, encrypt}
+	slices.Sort(strs)
+	h := sha1.Sum([]byte(strings.Join(strs, "")))
+	return hmac.Equal([]byte(hex.EncodeToString(h[:])), []byte(msgSignature))
+}
+
+// 回调信息的填充块大小
+const callbackBlockSize = 32
+
+// 函数解密企业微信回调的加密信息，返回信息内容与接收方 id。
+// encodingAESKey 为回调配置中的 43 位 EncodingAESKey，encrypted 为加密的信息内容 (验证回调地址时为 echostr)。
+// 解密方式为 AES-256-CBC，密钥为 EncodingAESKey 的 Base64 解码值，iv 为密钥的前 16 字节，填充方式为 PKCS#7 (块大小 32)。
+// 明文由 16 字节随机串、4 字节网络字节序的信息长度、信息内容与接收方 id 组成。
+// 调用方需要自行检查接收方 id 是否为自己的企业 id 或应用 id。
+func DecryptMessage(encodingAESKey, encrypted string) (msg []byte, receiveID string, err error) {
+	key, err := base64.StdEncoding.DecodeString(encodingAESKey + "=")
+	if err != nil {
+		return nil, "", fmt.Errorf("解密失败: EncodingAESKey 格式错误: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, "", fmt.Errorf("解密失败: EncodingAESKey 长度错误: %d", len(key))
+	}
+
+	bs, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, "", fmt.Errorf("解密失败: %w", err)
+	}
+	if len(bs) == 0 || len(bs)%aes.BlockSize != 0 {
+		return nil, "", fmt.Errorf("解密失败: 密文长度错误: %d", len(bs))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, "", fmt.Errorf("解密失败: %w", err)
+	}
+	out := make([]byte, len(bs))
+	cipher.NewCBCDecrypter(block, key[:aes.BlockSize]).CryptBlocks(out, bs)
+
+	out, err = unpad(out)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(out) < 20 {
+		return nil, "", errors.New("解密失败: 明文长度错误")
+	}
+	n := binary.BigEndian.Uint32(out[16:20])
+	if uint64(n) > uint64(len(out)-20) {
+		return nil, "", errors.New("解密失败: 信息长度错误")
+	}
+	return out[20 : 20+n], string(out[20+n:]), nil
+}
+
+// 函数去除 PKCS#7 填充。
+func unpad(bs []byte) ([]byte, error) {
+	n := int(bs[len(bs)-1])
+	if n < 1 || n > callbackBlockSize || n > len(bs) {
+		return nil, errors.New("解密失败: 填充错误")
+	}
+	for _, b := range bs[len(bs)-n:] {
+		if int(b) != n {
+			return nil, errors.New("解密失败: 填充错误")
+		}
+	}
+	return bs[:len(bs)-n], nil
+}
@@ -0,0 +1,89 @@
+package wx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kvii/bot/internal/errmatch"
+)
+
+// 官方文档中验证回调地址的示例
+const (
+	exampleToken          = "QDG6eK"
+	exampleEncodingAESKey = "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C"
+	exampleReceiveID      = "wx5823bf96d3bd56c7"
+	exampleTimestamp      = "1409659589"
+	exampleNonce          = "263014780"
+	exampleEchoStr        = "P9nAzCzyDtyTWESHep1vC5X9xho/qYX3Zpb4yKa9SKld1DsH3Iyt3tP3zNdtp+4RPcs8TgAE7OaBO+FZXvnaqQ=="
+	exampleSignature      = "5c45ff5e21c57e6ad56bac8758b79b1d9ac89fd3"
+)
+
+func TestVerifySignature(t *testing.T) {
+	testCases := []struct {
+		name      string // 测试项目
+		token     string // 令牌
+		encrypt   string // 加密内容
+		signature string // 签名
+		expect    bool   // 预期结果
+	}{
+		{name: "official example", token: exampleToken, encrypt: exampleEchoStr, signature: exampleSignature, expect: true},
+		{name: "wrong token", token: "wrong", encrypt: exampleEchoStr, signature: exampleSignature, expect: false},
+		{name: "tampered", token: exampleToken, encrypt: exampleEchoStr + "A", signature: exampleSignature, expect: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := VerifySignature(tc.token, exampleTimestamp, exampleNonce, tc.encrypt, tc.signature)
+			if got != tc.expect {
+				t.Fatalf("expect %t, got %t", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestDecryptMessage(t *testing.T) {
+	testCases := []struct {
+		name      string // 测试项目
+		key       string // EncodingAESKey
+		encrypted string // 密文
+		msg       string // 预期信息内容
+		receiveID string // 预期接收方 id
+		err       error  // 预期错误
+	}{
+		{
+			name:      "official example",
+			key:       exampleEncodingAESKey,
+			encrypted: exampleEchoStr,
+			msg:       "1616140317555161061",
+			receiveID: exampleReceiveID,
+		},
+		{
+			name:      "bad key",
+			key:       "short",
+			encrypted: exampleEchoStr,
+			err:       errmatch.Contains("EncodingAESKey"),
+		},
+		{
+			name:      "not base64",
+			key:       exampleEncodingAESKey,
+			encrypted: "!!!",
+			err:       errmatch.Contains("解密失败"),
+		},
+		{
+			name:      "bad length",
+			key:       exampleEncodingAESKey,
+			encrypted: "AAAA",
+			err:       errmatch.Contains("解密失败"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, receiveID, err := DecryptMessage(tc.key, tc.encrypted)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if string(msg) != tc.msg || receiveID != tc.receiveID {
+				t.Fatalf("expect %q %q, got %q %q", tc.msg, tc.receiveID, msg, receiveID)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package wx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+)
+
+// 分段发送文本信息。
+// 超过文本长度上限的信息会按字符边界拆分为多段，不会截断多字节字符。
+// 分段按原文顺序依次发送，遇到错误时立即停止并返回该错误。
+// 内容为空时返回 [ErrEmptyContent]。默认提醒成员 (@) 仅附加在第一段。
+func (c BotClient) SendTextChunked(ctx context.Context, msg string) error {
+	userIDs, mobiles := c.defaultMentions(ctx)
+	return c.sendTextChunked(ctx, msg, userIDs, mobiles)
+}
+
+// 分段发送文本信息，并在第一段提醒指定的群成员，见 [BotClient.SendTextChunked]。
+// userIDs 为 user id 列表，mobiles 为手机号列表，可以使用 [MentionAll] 提醒所有人。
+func (c BotClient) SendTextChunkedMention(ctx context.Context, msg string, userIDs []string, mobiles []string) error {
+	return c.sendTextChunked(ctx, msg, userIDs, mobiles)
+}
+
+func (c BotClient) sendTextChunked(ctx context.Context, msg string, userIDs, mobiles []string) error {
+	if err := c.checkEmpty(ctx, msg); err != nil {
+		return err
+	}
+
+	chunks := SplitByBytes(msg, MaxTextBytes)
+	c.logger().InfoContext(ctx, "分段发送文本消息", slog.Int("chunks", len(chunks)))
+
+	for i, chunk := range chunks {
+		if err := c.sendChunk(ctx, i, chunk, userIDs, mobiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 方法发送第 i 段 (从 0 开始) 文本。提醒成员仅附加在第一段。
+func (c BotClient) sendChunk(ctx context.Context, i int, chunk string, userIDs, mobiles []string) error {
+	text := &TextMessage{Content: chunk}
+	if i == 0 {
+		text.MentionedList, text.MentionedMobileList = userIDs, mobiles
+	}
+	err := c.send(ctx, Message{
+		MsgType: MessageTypeText,
+		Text:    text,
+	})
+	if err != nil {
+		c.logger().ErrorContext(ctx, "分段发送失败", slog.Int("index", i), slog.Any("err", err))
+		return fmt.Errorf("第 %d 段发送失败: %w", i+1, err)
+	}
+	return nil
+}
+
+// 函数按字符边界将字符串拆分为字节长度不超过 maxBytes 的多段，不会截断多字节字符。
+// maxBytes 小于一个字符的长度时，该字符单独成段，小于 1 时按 1 处理。空字符串返回包含一个空字符串的切片。
+// 可用于在发送前预览分段结果，[BotClient.SendTextChunked] 使用相同的拆分方式。
+func SplitByBytes(s string, maxBytes int) []string {
+	maxBytes = max(maxBytes, 1)
+	chunks := make([]string, 0, EstimateChunks(s, maxBytes))
+	for len(s) > maxBytes {
+		i := cut(s, maxBytes)
+		chunks = append(chunks, s[:i])
+		s = s[i:]
+	}
+	if s != "" || len(chunks) == 0 {
+		chunks = append(chunks, s)
+	}
+	return chunks
+}
+
+// 函数返回 [SplitByBytes] 拆分后的段数，不会分配内存。
+func EstimateChunks(s string, maxBytes int) int {
+	maxBytes = max(maxBytes, 1)
+	n := 0
+	for len(s) > maxBytes {
+		s = s[cut(s, maxBytes):]
+		n++
+	}
+	if s != "" || n == 0 {
+		n++
+	}
+	return n
+}
+
+// 函数返回 s 中不超过 maxBytes 字节的最长前缀的长度，前缀以字符边界结尾。
+// 调用方需要保证 len(s) > maxBytes > 0。maxBytes 小于第一个字符的长度时返回该字符的长度。
+func cut(s string, maxBytes int) int {
+	i := maxBytes
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	if i == 0 {
+		// maxBytes 小于一个字符的长度时，至少保留一个字符
+		_, i = utf8.DecodeRuneInString(s)
+	}
+	return i
+}
@@ -0,0 +1,93 @@
+package wx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitByBytes(t *testing.T) {
+	testCases := []struct {
+		name   string   // 测试项目
+		s      string   // 字符串
+		max    int      // 最大字节数
+		expect []string // 预期结果
+	}{
+		{name: "empty", s: "", max: 4, expect: []string{""}},
+		{name: "short", s: "abc", max: 4, expect: []string{"abc"}},
+		{name: "ascii", s: "abcdefghij", max: 4, expect: []string{"abcd", "efgh", "ij"}},
+		{name: "cjk", s: "测试测试", max: 7, expect: []string{"测试", "测试"}},
+		{name: "cjk boundary", s: "a测试b", max: 4, expect: []string{"a测", "试b"}},
+		{name: "max less than rune", s: "测试", max: 2, expect: []string{"测", "试"}},
+		{name: "cjk exact", s: "测试测试", max: 6, expect: []string{"测试", "测试"}},
+		{name: "cjk one short", s: "测试测试", max: 5, expect: []string{"测", "试", "测", "试"}},
+		{name: "cjk one over", s: "测试测试", max: 4, expect: []string{"测", "试", "测", "试"}},
+		{name: "mixed width", s: "ab测试😀c", max: 5, expect: []string{"ab测", "试", "😀c"}},
+		{name: "zero max", s: "a测", max: 0, expect: []string{"a", "测"}},
+		{name: "negative max", s: "ab", max: -1, expect: []string{"a", "b"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := SplitByBytes(tc.s, tc.max)
+			if strings.Join(got, "|") != strings.Join(tc.expect, "|") || len(got) != len(tc.expect) {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+			if n := EstimateChunks(tc.s, tc.max); n != len(tc.expect) {
+				t.Fatalf("expect %d chunks, got %d", len(tc.expect), n)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendTextChunked(t *testing.T) {
+	var got []string
+	var mentions [][]string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		var msg Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		got = append(got, msg.Text.Content)
+		mentions = append(mentions, msg.Text.MentionedList)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	msg := "a" + strings.Repeat("测", 1500)
+	err := client.SendTextChunked(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expect 3 chunks, got %d", len(got))
+	}
+	for i, chunk := range got {
+		if len(chunk) > MaxTextBytes || !utf8.ValidString(chunk) {
+			t.Fatalf("invalid chunk %d: %d bytes", i, len(chunk))
+		}
+	}
+	if strings.Join(got, "") != msg {
+		t.Fatalf("chunks do not match the original message")
+	}
+
+	got, mentions = nil, nil
+	err = client.SendTextChunkedMention(context.Background(), msg, []string{MentionAll}, nil)
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if len(mentions) != 3 || !slices.Equal(mentions[0], []string{"@all"}) || mentions[1] != nil || mentions[2] != nil {
+		t.Fatalf("expect mentions on the first chunk only, got %v", mentions)
+	}
+
+	err = client.SendTextChunked(context.Background(), " ")
+	if !errors.Is(err, ErrEmptyContent) {
+		t.Fatalf("expect %v, got %v", ErrEmptyContent, err)
+	}
+}
@@ -3,7 +3,9 @@ package wx
 import (
 	"bytes"
 	"cmp"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,17 +13,39 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/kvii/bot/internal/backoff"
+	"golang.org/x/time/rate"
 )
 
 // 信息类型
 type MessageType = string
 
 const (
-	MessageTypeText     MessageType = "text"     // 文本信息类型
-	MessageTypeMarkdown MessageType = "markdown" // markdown 信息类型
+	MessageTypeText         MessageType = "text"          // 文本信息类型
+	MessageTypeMarkdown     MessageType = "markdown"      // markdown 信息类型
+	MessageTypeMarkdownV2   MessageType = "markdown_v2"   // markdown_v2 信息类型。支持表格、图片等更多语法。
+	MessageTypeImage        MessageType = "image"         // 图片信息类型
+	MessageTypeNews         MessageType = "news"          // 图文信息类型
+	MessageTypeFile         MessageType = "file"          // 文件信息类型
+	MessageTypeVoice        MessageType = "voice"         // 语音信息类型
+	MessageTypeTemplateCard MessageType = "template_card" // 模板卡片信息类型
 )
 
+// 内容长度上限，单位为字节。
+// 发送前会检查内容长度，超过上限时返回 [ErrContentTooLong]。
+const (
+	MaxTextBytes     = 2048 // 文本内容长度上限
+	MaxMarkdownBytes = 4096 // markdown 内容长度上限
+)
+
+// 提醒所有人
+const MentionAll = "@all"
+
 // markdown 信息
 type MarkdownMessage struct {
 	Content string `json:"content"` // 是	markdown内容，最长不超过4096个字节，必须是utf8编码
@@ -29,9 +53,15 @@ type MarkdownMessage struct {
 
 // 信息
 type Message struct {
-	MsgType  MessageType      `json:"msgtype"`            // 信息类型
-	Text     *TextMessage     `json:"text,omitempty"`     // 文本信息
-	Markdown *MarkdownMessage `json:"markdown,omitempty"` // markdown 信息
+	MsgType      MessageType      `json:"msgtype"`                 // 信息类型
+	Text         *TextMessage     `json:"text,omitempty"`          // 文本信息
+	Markdown     *MarkdownMessage `json:"markdown,omitempty"`      // markdown 信息
+	MarkdownV2   *MarkdownMessage `json:"markdown_v2,omitempty"`   // markdown_v2 信息
+	Image        *ImageMessage    `json:"image,omitempty"`         // 图片信息
+	News         *NewsMessage     `json:"news,omitempty"`          // 图文信息
+	File         *FileMessage     `json:"file,omitempty"`          // 文件信息
+	Voice        *VoiceMessage    `json:"voice,omitempty"`         // 语音信息
+	TemplateCard *TemplateCard    `json:"template_card,omitempty"` // 模板卡片信息
 }
 
 // 文本信息
@@ -41,39 +71,383 @@ type TextMessage struct {
 	MentionedMobileList []string `json:"mentioned_mobile_list,omitempty"` // 否	手机号列表，提醒手机号对应的群成员(@某个成员)，@all 表示提醒所有人
 }
 
+// 图文信息
+type NewsMessage struct {
+	Articles []Article `json:"articles"` // 是	图文消息，一个图文消息支持1到8条图文
+}
+
+// 图文
+type Article struct {
+	Title       string `json:"title"`                 // 是	标题，不超过128个字节，超过会自动截断
+	Description string `json:"description,omitempty"` // 否	描述，不超过512个字节，超过会自动截断
+	URL         string `json:"url"`                   // 是	点击后跳转的链接。
+	PicURL      string `json:"picurl,omitempty"`      // 否	图文消息的图片链接，支持JPG、PNG格式，较好的效果为大图 1068*455，小图150*150。
+}
+
+// 文件信息
+type FileMessage struct {
+	MediaID string `json:"media_id"` // 是	文件id，通过下文的文件上传接口获取
+}
+
+// 语音信息
+type VoiceMessage struct {
+	MediaID string `json:"media_id"` // 是	语音文件id，通过下文的文件上传接口获取
+}
+
 // 发送响应
 type SendResponse struct {
 	ErrCode int    `json:"errcode"` // 错误码
 	ErrMsg  string `json:"errmsg"`  // 错误说明
 }
 
+// 发送结果。包含响应以及重试次数、耗时等可观测信息。
+type SendResult struct {
+	Attempts   int           // 请求次数，包含重试与压缩被拒后的不压缩请求。未发出请求时为 0。
+	Duration   time.Duration // 从第一次请求到返回的耗时，包含重试等待时间
+	StatusCode int           // 最后一次请求的 http 状态码。未收到响应时为 0。
+	Response   SendResponse  // 最后一次请求解析后的响应
+}
+
+// 接口错误。errcode 非 0 时返回。
+// 可以通过 errors.As 获取错误码。
+// 错误码为 93000 时，errors.Is(err, [ErrWebhookInvalid]) 为真。
+type APIError struct {
+	Code    int    // 错误码
+	Message string // 错误说明
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("响应异常: %d %s", e.Code, e.Message)
+}
+
+// 方法使 errors.Is 能将特定错误码匹配到对应的预定义错误。
+func (e APIError) Is(target error) bool {
+	return target == ErrWebhookInvalid && e.Code == codeInvalidWebhook
+}
+
+// 响应状态错误。响应状态码不为 200 时返回。
+// 可以通过 errors.As 获取响应头，如读取 Retry-After。
+// 开启 PreserveResponseBody 时，收到响应后发生的其他错误也会包装为该类型，此时 Err 为原错误。
+type HTTPError struct {
+	StatusCode int         // 响应状态码
+	Header     http.Header // 响应头
+	Body       []byte      // 响应内容
+	Err        error       // 原错误。仅在开启 PreserveResponseBody 时设置
+}
+
+func (e HTTPError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("响应状态错误: %d", e.StatusCode)
+}
+
+func (e HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// 接口调用超过限制的错误码
+const codeFreqOutOfLimit = 45009
+
+// 机器人地址无效或已被停用的错误码
+const codeInvalidWebhook = 93000
+
+// 函数判断错误是否由限流引起。
+func isRateLimited(err error) bool {
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == codeFreqOutOfLimit
+	}
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
 // 预定义错误
 var (
-	ErrNeedToken = errors.New("wx: need token") // 需要提供令牌
+	ErrNeedToken             = errors.New("wx: need token")                                        // 需要提供令牌
+	ErrProxyConflict         = errors.New("wx: ProxyURL conflicts with Client, Transport or Doer") // 代理配置冲突
+	ErrResponseTooLarge      = errors.New("wx: response too large")                                // 响应内容过大
+	ErrUnexpectedContentType = errors.New("wx: unexpected content type")                           // 响应类型错误
+	ErrMediaTooLarge         = errors.New("wx: media too large")                                   // 文件过大
+	ErrContentTooLong        = errors.New("wx: content too long")                                  // 内容过长
+	ErrInvalidKey            = errors.New("wx: invalid key")                                       // 令牌格式错误
+	ErrWebhookInvalid        = errors.New("wx: webhook invalid or disabled")                       // 机器人地址无效或已被停用
+	ErrNeedMentionResolver   = errors.New("wx: need MentionResolver to mention by name")           // 需要提供成员查找函数
+	ErrImageMismatch         = errors.New("wx: image md5 mismatch")                                // 图片 md5 与内容不一致
+	ErrEmptyContent          = errors.New("wx: empty content")                                     // 内容为空
+	ErrEmptyMediaID          = errors.New("wx: empty media id")                                    // 文件 id 为空
+	ErrUnsupportedMarkdown   = errors.New("wx: unsupported markdown")                              // markdown 包含不支持的语法
+	ErrUnsupportedColor      = errors.New("wx: unsupported font color")                            // 不支持的字体颜色
 )
 
+// 重试配置
+type RetryConfig struct {
+	MaxAttempts int           // 最大尝试次数。不大于 1 时不重试。
+	BaseDelay   time.Duration // 首次重试前的等待时间，之后每次翻倍，单次最长 5 分钟。
+}
+
+// 返回与接口频率限制 (每个机器人每分钟最多 20 条) 相匹配的限流器。
+func DefaultLimiter() *rate.Limiter {
+	return rate.NewLimiter(rate.Every(time.Minute/20), 20)
+}
+
+// 返回丢弃所有输出的 logger。
+// 将 BotClient 的 Logger 设置为该函数的返回值即可关闭所有日志。
+func NopLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// 返回适合突发流量的 http client。
+// 默认的 [http.DefaultClient] 每个主机只保留 2 个空闲连接，高并发发送时会频繁新建连接并耗尽临时端口。
+// 在多个 goroutine 间共享同一个 BotClient 时，建议将其 Client 设置为该函数的返回值。
+func DefaultPooledClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 20
+	t.IdleConnTimeout = 90 * time.Second
+	return &http.Client{Transport: t}
+}
+
 // 企业微信机器人客户端
 type BotClient struct {
-	Client  *http.Client // 底层 http client。不填则使用默认值。
-	Logger  *slog.Logger // 日志 logger。不填则使用默认值。
-	BaseURL string       // 接口基础地址。不填则使用默认值。
-	Key     string       // 机器人令牌。
+	Client      *http.Client // 底层 http client。不填则使用默认值。
+	Logger      *slog.Logger // 日志 logger。不填则使用默认值，设置为 NopLogger() 时不输出日志。
+	BaseURL     string       // 接口基础地址。不填则使用默认值。
+	UserAgent   string       // 请求的 User-Agent。不填则使用默认值 kvii-bot/1.0。
+	WebhookPath string       // 发送信息接口路径。不填则使用默认值 /cgi-bin/webhook/send。
+	Key         string       // 机器人令牌。ctx 中通过 ContextWithKey 保存的令牌优先。
+	Retry       RetryConfig  // 限流时的重试配置。不填则不重试。
+
+	// 客户端共享的重试预算。设置后每次重试消耗预算，预算耗尽时不再重试，
+	// 返回的错误包含 [ErrRetryBudgetExhausted]，可以在 OnSend 中据此统计。不填则不限制。
+	RetryBudget *RetryBudget
+
+	// 执行请求的 Doer，设置后优先于 Client 与 Transport 使用。
+	// 可用于在不启动 http 服务的情况下模拟响应，或接入自定义的重试逻辑。
+	Doer Doer
+
+	// 底层 http 传输层。仅在 Doer 与 Client 都为空时使用，用于在不接管 client 构造的情况下
+	// 注入链路追踪、指标统计或自定义请求头等中间件。
+	Transport http.RoundTripper
+
+	// 附加的请求头，如 API 网关的鉴权令牌。发送时会复制到每个请求上。
+	// Content-Type 由客户端决定，不能被覆盖；User-Agent 请使用 UserAgent 字段设置。
+	Headers http.Header
+
+	// 是否压缩较大的请求体。开启后，超过 1KB 的请求体会以 gzip 压缩发送，并设置 Content-Encoding: gzip。
+	// 注意：官方文档没有说明接口支持压缩请求。服务端以 415 拒绝压缩请求时，会自动改为不压缩重试一次，该请求计入 [SendResult.Attempts]。
+	Compress bool
+
+	// 代理地址，如 http://proxy.example.com:8080。仅在 Doer、Client 与 Transport 都为空时使用。
+	// 与 Doer、Client 或 Transport 同时设置时，发送时返回 [ErrProxyConflict]。
+	ProxyURL string
+
+	// 是否以 Debug 级别记录请求地址、请求内容与响应内容。
+	// 记录的请求地址中的令牌会被替换为 ***。
+	LogBody bool
+
+	// 幂等请求头名称，如 Idempotency-Key。不填则不添加。
+	// 每次发送会生成一个 UUID 作为请求头的值，同一信息的多次重试使用相同的值，
+	// 便于代理或网关对重复请求去重。
+	IdempotencyHeader string
+
+	// 客户端限流器。不填则不限流。
+	// 每次请求 (包括重试与压缩被拒后的不压缩请求) 前都会等待限流器放行，可以使用 [DefaultLimiter]。
+	Limiter *rate.Limiter
+
+	// 发送记录。不填则不记录，此时 LastSentAt 总是返回零值。
+	// 需要读取最近一次发送时间时，设置为 new(SendTracker)。
+	Tracker *SendTracker
+
+	// 发送前修改信息的钩子，在校验之后、序列化之前调用，可用于统一追加页脚或链路追踪标识等。
+	// 返回错误时中止发送并返回该错误。修改后的信息不会再次校验。
+	// 可以修改 MsgType，但不建议这样做。不填则不调用。
+	BeforeSend func(ctx context.Context, msg *Message) error
+
+	// 发送结束时的回调，成功与失败时都会调用，可用于接入指标统计。
+	// duration 为最后一次请求从发送前到响应解析完成的耗时，不包含限流等待与重试间隔，未发送请求时为 0。
+	OnSend func(msgType string, duration time.Duration, err error)
+
+	// 发送失败时的回调，参数为发送失败的信息与错误，可用于将信息写入死信队列后重新发送。
+	// 与 OnSend 不同，仅在失败时调用，并提供完整的信息；信息经过 BeforeSend 修改时为修改后的信息。
+	// SendJSON 发送的请求体不是 Message，失败时不会调用。不填则不调用。
+	OnError func(ctx context.Context, msg Message, err error)
+
+	// 试运行。开启后执行参数检查与序列化，并以 Info 级别记录请求内容，但不发送请求。
+	// 参数检查失败时仍然返回错误。
+	DryRun bool
+
+	// 从 ctx 中提取日志属性，如链路追踪 id。不填则不提取。
+	// 设置后，每条日志都会附加该函数返回的属性。
+	LogAttrsFromContext func(ctx context.Context) []slog.Attr
+
+	// 根据成员名称查找 user id，供 SendTextMentionByName 使用。不填则无法按名称提醒。
+	// 可以在此接入通讯录等目录服务。
+	MentionResolver func(ctx context.Context, name string) (userID string, err error)
+
+	// 是否严格检查 markdown 内容。开启后 SendMarkdown 会在发送前检查 markdown 信息不支持的语法
+	// (表格、图片)，包含时返回 [ErrUnsupportedMarkdown] 并列出对应内容。这些语法在 markdown_v2 中可用。
+	// 不开启时不做检查，不支持的语法会按原文显示。
+	StrictMarkdown bool
+
+	// 默认提醒的成员，如值班人员。设置后 SendText、SendTextChunked、SendReader 与 AsyncClient.SendText
+	// 会自动提醒这些成员，分段发送时仅附加在第一段。显式指定提醒成员的方法 (如 SendTextMention) 不使用默认值。
+	// 单次发送不需要提醒时，使用 WithoutDefaultMentions 包装 ctx。
+	DefaultMentionedList       []string // 默认提醒的 user id 列表
+	DefaultMentionedMobileList []string // 默认提醒的手机号列表
+
+	// json 序列化与反序列化函数，可替换为更快的实现。不填则使用 encoding/json。
+	Marshal   func(v any) ([]byte, error)
+	Unmarshal func(data []byte, v any) error
+
+	// 自定义的响应检查函数，参数为响应状态码与响应内容，返回错误表示发送失败。
+	// 设置后替代默认的状态码、响应类型与响应码检查，适用于会改写响应格式的网关。
+	// 返回 [APIError] 或 [HTTPError] 时，限流重试等依赖错误类型的逻辑仍然有效。
+	// 响应内容能解析为 json 时，仍会解析到返回的响应中。不填则使用默认检查。
+	SuccessFunc func(status int, body []byte) error
+
+	// 是否在发送失败时保留完整的响应内容。开启后，响应码错误、响应类型错误等收到响应后发生的错误
+	// 都会包装为 [HTTPError]，可以通过 errors.As 读取响应内容，原错误仍可通过 errors.Is 与 errors.As 匹配。
+	// 响应内容可能较大，因此默认不开启。响应状态码不为 200 时总会返回带有响应内容的 [HTTPError]。
+	PreserveResponseBody bool
+
+	// 响应内容的大小上限，单位为字节。不填则使用默认值 1MB。
+	// 用于避免异常的代理或网关返回过大的响应耗尽内存，超过时返回 [ErrResponseTooLarge]。
+	MaxResponseBytes int64
+
+	// 默认超时时间。ctx 没有截止时间时使用，不填则不设置超时。
+	// ctx 自带的截止时间总是优先。
+	Timeout time.Duration
+
+	clock clock // 时间来源，仅用于测试
 }
 
 // 方法发送文本信息。
-// 目前只支持文本信息。
+// 目前只支持文本信息。内容为空或只包含空白字符时返回 [ErrEmptyContent]，不会发送请求。
+// 设置了默认提醒成员时会同时提醒这些成员。
 func (c BotClient) SendText(ctx context.Context, msg string) error {
-	c.logger().InfoContext(ctx, "发送文本消息", slog.String("msg", msg))
+	c.logger().InfoContext(ctx, "发送文本消息")
 
+	if err := c.checkEmpty(ctx, msg); err != nil {
+		return err
+	}
+	if err := c.checkLength(ctx, msg, MaxTextBytes); err != nil {
+		return err
+	}
+
+	userIDs, mobiles := c.defaultMentions(ctx)
 	return c.send(ctx, Message{
 		MsgType: MessageTypeText,
-		Text:    &TextMessage{Content: msg},
+		Text: &TextMessage{
+			Content:             msg,
+			MentionedList:       userIDs,
+			MentionedMobileList: mobiles,
+		},
 	})
 }
 
+// 方法返回默认提醒的成员。ctx 经过 [WithoutDefaultMentions] 包装时返回空值。
+func (c BotClient) defaultMentions(ctx context.Context) (userIDs, mobiles []string) {
+	if skip, _ := ctx.Value(noDefaultMentionsKey{}).(bool); skip {
+		return nil, nil
+	}
+	return c.DefaultMentionedList, c.DefaultMentionedMobileList
+}
+
+// 方法按 format 格式化后发送文本信息，见 [BotClient.SendText]。
+// 格式化后的内容超过 [MaxTextBytes] 时返回 [ErrContentTooLong]。
+func (c BotClient) SendTextf(ctx context.Context, format string, args ...any) error {
+	return c.SendText(ctx, fmt.Sprintf(format, args...))
+}
+
+// 方法最多等待 d 时长发送文本信息，见 [BotClient.SendText]。
+// 超时后返回 context.DeadlineExceeded。ctx 的截止时间更早时以 ctx 为准。
+func (c BotClient) SendTextTimeout(ctx context.Context, d time.Duration, msg string) error {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	return c.SendText(ctx, msg)
+}
+
+// 发送文本信息，并提醒指定的群成员。
+// userIDs 为 user id 列表，mobiles 为手机号列表，可以使用 [MentionAll] 提醒所有人。
+func (c BotClient) SendTextMention(ctx context.Context, msg string, userIDs []string, mobiles []string) error {
+	c.logger().InfoContext(ctx, "发送文本消息", slog.Int("mentioned", len(userIDs)+len(mobiles)))
+
+	if err := c.checkEmpty(ctx, msg); err != nil {
+		return err
+	}
+	if err := c.checkLength(ctx, msg, MaxTextBytes); err != nil {
+		return err
+	}
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeText,
+		Text: &TextMessage{
+			Content:             msg,
+			MentionedList:       userIDs,
+			MentionedMobileList: mobiles,
+		},
+	})
+}
+
+// 发送文本信息，并提醒所有人。
+func (c BotClient) SendTextMentionAll(ctx context.Context, msg string) error {
+	return c.SendTextMention(ctx, msg, []string{MentionAll}, nil)
+}
+
+// 发送文本信息，并按名称提醒群成员。
+// 名称通过 MentionResolver 转换为 user id，未设置时返回 [ErrNeedMentionResolver]。
+// [MentionAll] 不经过转换，直接提醒所有人。
+func (c BotClient) SendTextMentionByName(ctx context.Context, msg string, names []string) error {
+	if c.MentionResolver == nil {
+		c.logger().ErrorContext(ctx, "需要提供成员查找函数")
+		return ErrNeedMentionResolver
+	}
+
+	resolved := make(map[string]string, len(names))
+	userIDs := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == MentionAll {
+			userIDs = append(userIDs, MentionAll)
+			continue
+		}
+		id, ok := resolved[name]
+		if !ok {
+			var err error
+			id, err = c.MentionResolver(ctx, name)
+			if err != nil {
+				c.logger().ErrorContext(ctx, "成员查找失败", slog.String("name", name), slog.Any("err", err))
+				return fmt.Errorf("成员 %q 查找失败: %w", name, err)
+			}
+			resolved[name] = id
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	return c.SendTextMention(ctx, msg, userIDs, nil)
+}
+
 // 发送 Markdown 信息。
+// 内容为空或只包含空白字符时返回 [ErrEmptyContent]，不会发送请求。
+// 开启 StrictMarkdown 时，内容包含不支持的语法会返回 [ErrUnsupportedMarkdown]。
 func (c BotClient) SendMarkdown(ctx context.Context, msg string) error {
-	c.logger().InfoContext(ctx, "发送 Markdown 消息", slog.String("msg", msg))
+	c.logger().InfoContext(ctx, "发送 Markdown 消息")
+
+	if err := c.checkEmpty(ctx, msg); err != nil {
+		return err
+	}
+	if err := c.checkLength(ctx, msg, MaxMarkdownBytes); err != nil {
+		return err
+	}
+	if c.StrictMarkdown {
+		if tokens := unsupportedMarkdown(msg); len(tokens) > 0 {
+			c.logger().ErrorContext(ctx, "markdown 包含不支持的语法", slog.Any("tokens", tokens))
+			return fmt.Errorf("%w: %q", ErrUnsupportedMarkdown, tokens)
+		}
+	}
 
 	return c.send(ctx, Message{
 		MsgType:  MessageTypeMarkdown,
@@ -81,6 +455,63 @@ func (c BotClient) SendMarkdown(ctx context.Context, msg string) error {
 	})
 }
 
+// 发送 markdown_v2 信息。
+// markdown_v2 支持表格、图片等更多语法，内容长度上限与 markdown 相同。
+func (c BotClient) SendMarkdownV2(ctx context.Context, msg string) error {
+	c.logger().InfoContext(ctx, "发送 Markdown V2 消息")
+
+	if err := c.checkEmpty(ctx, msg); err != nil {
+		return err
+	}
+	if err := c.checkLength(ctx, msg, MaxMarkdownBytes); err != nil {
+		return err
+	}
+
+	return c.send(ctx, Message{
+		MsgType:    MessageTypeMarkdownV2,
+		MarkdownV2: &MarkdownMessage{Content: msg},
+	})
+}
+
+// 发送图文信息。
+// 图文数量需要在 1 到 8 条之间。
+func (c BotClient) SendNews(ctx context.Context, articles []Article) error {
+	c.logger().InfoContext(ctx, "发送图文消息", slog.Int("count", len(articles)))
+
+	if n := len(articles); n < 1 || n > 8 {
+		c.logger().ErrorContext(ctx, "图文数量错误", slog.Int("count", n))
+		return fmt.Errorf("图文数量错误: %d, 需要在 1 到 8 条之间", n)
+	}
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeNews,
+		News:    &NewsMessage{Articles: articles},
+	})
+}
+
+// 发送文件信息。
+// mediaID 需要先通过 [BotClient.UploadMedia] 上传文件获取。
+func (c BotClient) SendFile(ctx context.Context, mediaID string) error {
+	c.logger().InfoContext(ctx, "发送文件消息", slog.String("mediaID", mediaID))
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeFile,
+		File:    &FileMessage{MediaID: mediaID},
+	})
+}
+
+// 发送语音信息。
+// mediaID 需要先通过 [BotClient.UploadMedia] 以 [MediaTypeVoice] 类型上传语音文件获取。
+// 语音文件仅支持 amr 格式，大小不超过 2MB，播放长度不超过 60s。mediaID 为空时返回 [ErrEmptyMediaID]。
+func (c BotClient) SendVoice(ctx context.Context, mediaID string) error {
+	c.logger().InfoContext(ctx, "发送语音消息", slog.String("mediaID", mediaID))
+
+	return c.send(ctx, Message{
+		MsgType: MessageTypeVoice,
+		Voice:   &VoiceMessage{MediaID: mediaID},
+	})
+}
+
 // 方法发送信息。
 // 目前只支持文本信息。
 func (c BotClient) Send(ctx context.Context, msg Message) error {
@@ -88,72 +519,594 @@ func (c BotClient) Send(ctx context.Context, msg Message) error {
 	return c.send(ctx, msg)
 }
 
-func (c BotClient) send(ctx context.Context, msg Message) error {
+// 方法按顺序依次发送多条信息，适用于需要保证顺序的多段公告。
+// 遇到错误时立即停止，返回的错误中包含失败信息的序号 (从 1 开始)，之后的信息不会发送。
+// 每条信息发送前检查 ctx，ctx 结束时返回 ctx.Err()。
+func (c BotClient) SendAll(ctx context.Context, msgs []Message) error {
+	c.logger().InfoContext(ctx, "依次发送消息", slog.Int("count", len(msgs)))
+
+	for i, msg := range msgs {
+		if err := ctx.Err(); err != nil {
+			c.logger().ErrorContext(ctx, "发送中止", slog.Int("index", i), slog.Any("err", err))
+			return fmt.Errorf("第 %d 条信息发送前中止 (共 %d 条): %w", i+1, len(msgs), err)
+		}
+		if err := c.send(ctx, msg); err != nil {
+			c.logger().ErrorContext(ctx, "依次发送失败", slog.Int("index", i), slog.Any("err", err))
+			return fmt.Errorf("第 %d 条信息发送失败 (共 %d 条): %w", i+1, len(msgs), err)
+		}
+	}
+	return nil
+}
+
+// 方法发送信息，并返回解析后的响应。
+// 响应异常时同时返回响应与错误，便于调用方根据错误码自行处理。
+func (c BotClient) SendRaw(ctx context.Context, msg Message) (SendResponse, error) {
+	res, err := c.SendWithResult(ctx, msg)
+	return res.Response, err
+}
+
+// 方法发送信息，并返回包含请求次数、耗时与状态码的发送结果。
+// 与 [BotClient.SendRaw] 相同，响应异常时同时返回结果与错误。
+func (c BotClient) SendWithResult(ctx context.Context, msg Message) (SendResult, error) {
+	res, err := c.sendPayload(ctx, msg.MsgType, func() ([]byte, error) {
+		if err := msg.Validate(); err != nil {
+			c.logger().ErrorContext(ctx, "信息校验失败", slog.Any("err", err))
+			return nil, err
+		}
+		if c.BeforeSend != nil {
+			if err := c.BeforeSend(ctx, &msg); err != nil {
+				c.logger().ErrorContext(ctx, "发送前钩子返回错误", slog.Any("err", err))
+				return nil, err
+			}
+		}
+		bs, err := c.marshal(msg)
+		if err != nil {
+			c.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
+			return nil, err
+		}
+		return bs, nil
+	})
+	if err != nil && c.OnError != nil {
+		c.OnError(ctx, msg, err)
+	}
+	return res, err
+}
+
+// 方法发送调用方提供的 json 请求体，适用于本包尚未支持的信息类型。
+// 请求体会原样发送，只检查其是否为合法的 json 对象，且 msgtype 为非空字符串；
+// 地址、令牌、重试与响应处理与其他方法相同。
+func (c BotClient) SendJSON(ctx context.Context, raw json.RawMessage) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		c.logger().ErrorContext(ctx, "json 格式错误", slog.Any("err", err))
+		return errors.New("json 格式错误: 需要为 json 对象")
+	}
+	var msgType MessageType
+	if err := json.Unmarshal(fields["msgtype"], &msgType); err != nil || msgType == "" {
+		c.logger().ErrorContext(ctx, "信息类型错误", slog.Any("err", err))
+		return errors.New("json 格式错误: msgtype 需要为非空字符串")
+	}
+	c.logger().InfoContext(ctx, "发送 json 消息", slog.String("msgType", msgType))
+
+	_, err := c.sendPayload(ctx, msgType, func() ([]byte, error) {
+		return raw, nil
+	})
+	return err
+}
+
+// 方法发送 encode 生成的请求体，并返回发送结果。
+// encode 在令牌检查前调用，用于校验与序列化信息。
+func (c BotClient) sendPayload(ctx context.Context, msgType MessageType, encode func() ([]byte, error)) (res SendResult, err error) {
+	c = c.withContextKey(ctx)
+
+	var start time.Time       // 第一次请求前的时间
+	var elapsed time.Duration // 最后一次请求的耗时
+	defer func() {
+		if !start.IsZero() {
+			res.Duration = time.Since(start)
+		}
+	}()
+	if c.OnSend != nil {
+		defer func() {
+			c.OnSend(msgType, elapsed, err)
+		}()
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	bs, err := encode()
+	if err != nil {
+		return SendResult{}, err
+	}
+
 	if c.Key == "" {
 		c.logger().ErrorContext(ctx, "需要提供令牌")
-		return ErrNeedToken
+		return SendResult{}, ErrNeedToken
 	}
 
-	u, err := url.Parse(c.baseURL())
+	u, err := c.webhookURL()
 	if err != nil {
 		c.logger().ErrorContext(ctx, "URL 解析失败", slog.Any("err", err))
-		return err
+		return SendResult{}, err
+	}
+
+	c.debugBody(ctx, "请求内容", slog.String("url", c.redact(u)), slog.String("body", string(bs)))
+
+	if c.DryRun {
+		c.logger().InfoContext(ctx, "试运行，跳过发送", slog.String("url", c.redact(u)), slog.String("body", string(bs)))
+		return SendResult{}, nil
+	}
+
+	header := make(http.Header)
+	if c.IdempotencyHeader != "" {
+		header.Set(c.IdempotencyHeader, newUUID())
+	}
+
+	start = time.Now()
+	for attempt := 1; ; attempt++ {
+		if err = c.wait(ctx); err != nil {
+			return res, err
+		}
+
+		var n int
+		reqStart := time.Now()
+		res.Response, res.StatusCode, n, err = c.post(ctx, u, header, bs)
+		res.Attempts += n
+		elapsed = time.Since(reqStart)
+		if err == nil {
+			break
+		}
+		if attempt >= c.Retry.MaxAttempts || !isRateLimited(err) {
+			return res, err
+		}
+		if !c.RetryBudget.Allow() {
+			c.logger().WarnContext(ctx, "重试预算耗尽，放弃重试", slog.Int("attempt", attempt))
+			return res, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+
+		delay := backoff.Delay(c.Retry.BaseDelay, attempt)
+		c.logger().WarnContext(ctx, "触发限流，等待重试", slog.Int("attempt", attempt), slog.Duration("delay", delay))
+
+		if err = c.sleep(ctx, delay); err != nil {
+			return res, err
+		}
+	}
+
+	c.Tracker.Record(c.now())
+	c.logger().InfoContext(ctx, "消息发送成功")
+	return res, nil
+}
+
+// 方法返回发送信息时请求的地址，其中的令牌会被替换为 ***。
+// 与发送时使用相同的地址构造逻辑，可用于排查问题。未设置 Key 时返回 [ErrNeedToken]。
+func (c BotClient) RequestURL() (string, error) {
+	if c.Key == "" {
+		return "", ErrNeedToken
+	}
+	u, err := c.webhookURL()
+	if err != nil {
+		return "", err
 	}
-	u = u.JoinPath("/cgi-bin/webhook/send")
+	return c.redact(u), nil
+}
+
+// 方法构造 webhook 地址。
+func (c BotClient) webhookURL() (string, error) {
+	return buildURL(c.BaseURL, c.WebhookPath, c.Key)
+}
+
+// 函数构造 webhook 地址。base 与 path 为空时使用默认值，key 作为查询参数 key 的值并进行转义。
+// base 中已有的路径与查询参数会被保留。
+func buildURL(base, path, key string) (string, error) {
+	u, err := url.Parse(cmp.Or(base, defaultBaseURL))
+	if err != nil {
+		return "", err
+	}
+	u = u.JoinPath(cmp.Or(path, defaultWebhookPath))
 	q := u.Query()
-	q.Set("key", c.Key)
+	q.Set("key", key)
 	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
 
-	bs, err := json.Marshal(msg)
+// 方法等待限流器放行。ctx 结束时返回 ctx.Err()。
+func (c BotClient) wait(ctx context.Context) error {
+	if c.Limiter == nil {
+		return nil
+	}
+	err := c.Limiter.Wait(ctx)
 	if err != nil {
-		c.logger().ErrorContext(ctx, "参数序列化失败", slog.Any("err", err))
+		c.logger().ErrorContext(ctx, "限流等待失败", slog.Any("err", err))
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		return err
 	}
+	return nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewBuffer(bs))
+// 方法发送一次信息请求。
+// header 为附加的请求头，返回解析后的响应、http 状态码与实际发出的请求数。
+// 开启压缩且请求体超过阈值时先压缩发送，服务端拒绝时改为不压缩重试。
+func (c BotClient) post(ctx context.Context, u string, header http.Header, bs []byte) (SendResponse, int, int, error) {
+	if c.Compress && len(bs) > compressThreshold {
+		data, status, err := c.postOnce(ctx, u, header, bs, true)
+		if !rejectsGzip(err) {
+			return data, status, 1, err
+		}
+		c.logger().WarnContext(ctx, "服务端不接受压缩请求，改为不压缩重试", slog.Any("err", err))
+		// 不压缩重试是一次新的请求，同样需要等待限流器放行
+		if err = c.wait(ctx); err != nil {
+			return data, status, 1, err
+		}
+		data, status, err = c.postOnce(ctx, u, header, bs, false)
+		return data, status, 2, err
+	}
+	data, status, err := c.postOnce(ctx, u, header, bs, false)
+	return data, status, 1, err
+}
+
+// 方法发送一次信息请求。compress 为 true 时以 gzip 压缩请求体。
+func (c BotClient) postOnce(ctx context.Context, u string, header http.Header, bs []byte, compress bool) (SendResponse, int, error) {
+	if compress {
+		var err error
+		bs, err = gzipBytes(bs)
+		if err != nil {
+			c.logger().ErrorContext(ctx, "请求体压缩失败", slog.Any("err", err))
+			return SendResponse{}, 0, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(bs))
 	if err != nil {
 		c.logger().ErrorContext(ctx, "请求创建失败", slog.Any("err", err))
-		return err
+		return SendResponse{}, 0, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 
-	resp, err := c.client().Do(req)
+	resp, body, err := c.roundTrip(ctx, req)
 	if err != nil {
-		c.logger().ErrorContext(ctx, "请求发送失败", slog.Any("err", err))
+		return SendResponse{}, 0, err
+	}
+	data, err := c.checkSend(ctx, resp, body)
+	if err != nil && c.PreserveResponseBody {
+		err = preserveBody(err, resp, body)
+	}
+	return data, resp.StatusCode, err
+}
+
+// 方法检查发送信息的响应，并返回解析后的响应。
+func (c BotClient) checkSend(ctx context.Context, resp *http.Response, bs []byte) (SendResponse, error) {
+	var data SendResponse
+	if c.SuccessFunc != nil {
+		c.unmarshal(bs, &data)
+		if err := c.SuccessFunc(resp.StatusCode, bs); err != nil {
+			c.logger().ErrorContext(ctx, "响应异常", slog.Any("err", err))
+			return data, err
+		}
+		return data, nil
+	}
+
+	err := c.checkResponse(ctx, resp, bs, &data)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	if data.ErrCode != 0 {
+		c.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.ErrCode), slog.String("msg", data.ErrMsg))
+		return data, APIError{Code: data.ErrCode, Message: data.ErrMsg}
+	}
+	return data, nil
+}
+
+// 函数将响应状态码、响应头与响应内容附加到错误上，返回 [HTTPError]。
+// err 中已经包含 [HTTPError] 时原样返回。
+func preserveBody(err error, resp *http.Response, body []byte) error {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
 		return err
 	}
+	return HTTPError{StatusCode: resp.StatusCode, Header: resp.Header, Body: body, Err: err}
+}
+
+// 函数判断错误是否表示服务端不接受压缩请求。
+// 只有 415 表示不支持的内容编码，其他 400 等错误与压缩无关，不会改为不压缩重试。
+func rejectsGzip(err error) bool {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusUnsupportedMediaType
+	}
+	return false
+}
+
+// 压缩请求体的大小阈值，单位为字节
+const compressThreshold = 1 << 10
+
+// 函数以 gzip 压缩数据。
+func gzipBytes(bs []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(bs); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c BotClient) send(ctx context.Context, msg Message) error {
+	_, err := c.SendRaw(ctx, msg)
+	return err
+}
+
+// 方法发送请求，并返回响应与读取的响应内容。返回时响应体已关闭。
+func (c BotClient) roundTrip(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	for k, v := range c.Headers {
+		key := http.CanonicalHeaderKey(k)
+		if key == "Content-Type" {
+			continue
+		}
+		req.Header[key] = slices.Clone(v)
+	}
+	req.Header.Set("User-Agent", c.userAgent())
+
+	client, err := c.client()
+	if err != nil {
+		c.logger().ErrorContext(ctx, "http client 配置错误", slog.Any("err", err))
+		return nil, nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// http.Client 出错时响应体已关闭，但自定义的 Doer 可能同时返回响应与错误
+		if resp != nil && resp.Body != nil {
+			resp.Body.Close()
+		}
+		err = c.redactErr(err)
+		c.logger().ErrorContext(ctx, "请求发送失败", slog.Any("err", err))
+		return nil, nil, err
+	}
 	defer resp.Body.Close()
 
-	bs, err = io.ReadAll(resp.Body)
+	// ctx 结束时关闭响应体，避免读取缓慢的响应时超出截止时间
+	stop := context.AfterFunc(ctx, func() { resp.Body.Close() })
+	defer stop()
+
+	limit := c.maxResponseBytes()
+	bs, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
 		c.logger().ErrorContext(ctx, "响应读取失败", slog.Any("err", err))
+		return nil, nil, err
+	}
+	if int64(len(bs)) > limit {
+		c.logger().ErrorContext(ctx, "响应内容过大", slog.Int("status-code", resp.StatusCode), slog.Int64("limit", limit))
+		return nil, nil, fmt.Errorf("%w: 响应状态码 %d, 超过 %d 字节", ErrResponseTooLarge, resp.StatusCode, limit)
+	}
+
+	c.debugBody(ctx, "响应内容", slog.Int("status-code", resp.StatusCode), slog.String("body", string(bs)))
+	return resp, bs, nil
+}
+
+// 方法发送请求，并将 json 响应解析到 data 中。
+func (c BotClient) do(ctx context.Context, req *http.Request, data any) error {
+	resp, bs, err := c.roundTrip(ctx, req)
+	if err != nil {
 		return err
 	}
+	return c.checkResponse(ctx, resp, bs, data)
+}
 
+// 方法检查响应状态码与响应类型，并将 json 响应内容解析到 data 中。
+func (c BotClient) checkResponse(ctx context.Context, resp *http.Response, bs []byte, data any) error {
 	if resp.StatusCode != http.StatusOK {
 		c.logger().ErrorContext(ctx, "响应状态错误", slog.Int("status-code", resp.StatusCode), slog.Any("body", bytes.NewBuffer(bs)))
-		return fmt.Errorf("响应状态错误: %d", resp.StatusCode)
+		return HTTPError{StatusCode: resp.StatusCode, Header: resp.Header, Body: bs}
 	}
-	if mt := resp.Header.Get("Content-Type"); !strings.HasPrefix(mt, "application/json") {
+	if mt := resp.Header.Get("Content-Type"); !isJSON(mt) {
 		c.logger().ErrorContext(ctx, "响应类型错误", slog.String("content-type", mt), slog.Any("body", bytes.NewBuffer(bs)))
-		return fmt.Errorf("响应类型错误: %s", mt)
+		return fmt.Errorf("%w: %s, 响应内容: %q", ErrUnexpectedContentType, mt, snippet(bs))
 	}
 
-	var data SendResponse
-	err = json.Unmarshal(bs, &data)
+	err := c.unmarshal(bs, data)
 	if err != nil {
 		c.logger().ErrorContext(ctx, "响应解析失败", slog.Any("err", err), slog.Any("body", bytes.NewBuffer(bs)))
 		return err
 	}
-	if data.ErrCode != 0 {
-		c.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.ErrCode), slog.String("msg", data.ErrMsg))
-		return fmt.Errorf("响应异常: %d %s", data.ErrCode, data.ErrMsg)
+	return nil
+}
+
+// 方法检查内容的字节长度是否超过上限。
+func (c BotClient) checkLength(ctx context.Context, content string, limit int) error {
+	err := checkBytes(content, limit)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "内容过长", slog.Int("size", len(content)), slog.Int("limit", limit))
 	}
+	return err
+}
 
-	c.logger().InfoContext(ctx, "消息发送成功")
+// 方法检查内容是否为空，内容为空或只包含空白字符时返回 [ErrEmptyContent]。
+// 在发送前检查，避免一次必然失败的请求。
+func (c BotClient) checkEmpty(ctx context.Context, content string) error {
+	if strings.TrimSpace(content) == "" {
+		c.logger().ErrorContext(ctx, "内容为空")
+		return ErrEmptyContent
+	}
 	return nil
 }
 
-func (c BotClient) logger() *slog.Logger { return cmp.Or(c.Logger, slog.Default()) }
-func (c BotClient) client() *http.Client { return cmp.Or(c.Client, http.DefaultClient) }
-func (c BotClient) baseURL() string      { return cmp.Or(c.BaseURL, "https://qyapi.weixin.qq.com") }
+// 方法在 ctx 没有截止时间时，为其附加默认超时时间。
+func (c BotClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+// 方法在开启 LogBody 时以 Debug 级别记录日志。
+func (c BotClient) debugBody(ctx context.Context, msg string, args ...any) {
+	if c.LogBody {
+		c.logger().DebugContext(ctx, msg, args...)
+	}
+}
+
+// 方法将地址中的令牌替换为 ***。
+func (c BotClient) redact(u string) string {
+	if c.Key == "" {
+		return u
+	}
+	return strings.ReplaceAll(u, url.QueryEscape(c.Key), "***")
+}
+
+// 方法将请求错误中地址包含的令牌替换为 ***。
+// http.Client 返回的 *url.Error 在错误信息中带有完整的请求地址。
+func (c BotClient) redactErr(err error) error {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	redacted := *ue
+	redacted.URL = c.redact(ue.URL)
+	return &redacted
+}
+
+// 函数生成随机的 UUID (版本 4)。
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// 错误信息中响应内容片段的最大长度
+const maxSnippetBytes = 256
+
+// 函数截取响应内容的开头部分，用于错误信息。
+func snippet(bs []byte) string {
+	if len(bs) > maxSnippetBytes {
+		bs = bs[:maxSnippetBytes]
+	}
+	return strings.ToValidUTF8(string(bs), "")
+}
+
+// 默认值
+const (
+	defaultBaseURL     = "https://qyapi.weixin.qq.com" // 接口基础地址
+	defaultWebhookPath = "/cgi-bin/webhook/send"       // webhook 接口路径
+)
+
+func (c BotClient) baseURL() string     { return cmp.Or(c.BaseURL, defaultBaseURL) }
+func (c BotClient) userAgent() string   { return cmp.Or(c.UserAgent, "kvii-bot/1.0") }
+func (c BotClient) webhookPath() string { return cmp.Or(c.WebhookPath, defaultWebhookPath) }
+
+// 响应内容的默认大小上限
+const defaultMaxResponseBytes = 1 << 20
+
+func (c BotClient) maxResponseBytes() int64 {
+	if c.MaxResponseBytes <= 0 {
+		return defaultMaxResponseBytes
+	}
+	return c.MaxResponseBytes
+}
+
+func (c BotClient) logger() *slog.Logger {
+	l := cmp.Or(c.Logger, slog.Default())
+	if c.LogAttrsFromContext == nil {
+		return l
+	}
+	return slog.New(contextHandler{l.Handler(), c.LogAttrsFromContext})
+}
+
+func (c BotClient) marshal(v any) ([]byte, error) {
+	if c.Marshal != nil {
+		return c.Marshal(v)
+	}
+	return json.Marshal(v)
+}
+
+func (c BotClient) unmarshal(data []byte, v any) error {
+	if c.Unmarshal != nil {
+		return c.Unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// 执行 http 请求的接口。*http.Client 实现了该接口。
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// 方法返回执行请求使用的 Doer。
+func (c BotClient) client() (Doer, error) {
+	if c.ProxyURL != "" {
+		if c.Doer != nil || c.Client != nil || c.Transport != nil {
+			return nil, ErrProxyConflict
+		}
+		return proxyClient(c.ProxyURL)
+	}
+	if c.Doer != nil {
+		return c.Doer, nil
+	}
+	if c.Client == nil && c.Transport != nil {
+		return &http.Client{Transport: c.Transport}, nil
+	}
+	return cmp.Or(c.Client, http.DefaultClient), nil
+}
+
+// 按代理地址缓存的 http client，以便复用连接。
+var proxyClients sync.Map
+
+// 函数返回使用指定代理的 http client。
+func proxyClient(proxyURL string) (*http.Client, error) {
+	if v, ok := proxyClients.Load(proxyURL); ok {
+		return v.(*http.Client), nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("代理地址错误: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("代理地址错误: %q", proxyURL)
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyURL(u)
+	v, _ := proxyClients.LoadOrStore(proxyURL, &http.Client{Transport: t})
+	return v.(*http.Client), nil
+}
+
+// 从 ctx 中提取日志属性的 slog.Handler
+type contextHandler struct {
+	slog.Handler
+	attrs func(ctx context.Context) []slog.Attr
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(h.attrs(ctx)...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs), h.attrs}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name), h.attrs}
+}
+
+// 函数判断响应类型是否为 json。忽略大小写，兼容 text/json 与 charset 等参数。
+func isJSON(contentType string) bool {
+	mt := strings.ToLower(strings.TrimSpace(contentType))
+	return strings.HasPrefix(mt, "application/json") || strings.HasPrefix(mt, "text/json")
+}
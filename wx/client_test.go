@@ -1,23 +1,55 @@
 package wx
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/kvii/bot/internal/errmatch"
+	"golang.org/x/time/rate"
 )
 
+// 函数启动使用 handler 的测试服务器，并返回指向该服务器的客户端。
+// 客户端使用测试令牌，详细模式下输出日志。服务器在测试结束时关闭。
+func newTestServer(t *testing.T, handler http.Handler) (BotClient, *httptest.Server) {
+	t.Helper()
+
+	logger := NopLogger()
+	if testing.Verbose() {
+		logger = slog.Default()
+	}
+
+	s := httptest.NewServer(handler)
+	t.Cleanup(s.Close)
+
+	return BotClient{
+		Client:  s.Client(),
+		Logger:  logger,
+		BaseURL: s.URL,
+		Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+	}, s
+}
+
 func TestBotClientSendText(t *testing.T) {
 	var logger *slog.Logger
 	if testing.Verbose() {
 		logger = slog.Default()
 	} else {
-		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		logger = NopLogger()
 	}
 
 	var mux http.ServeMux
@@ -81,7 +113,7 @@ func TestBotClientSendText(t *testing.T) {
 			},
 			ctx: context.Background(),
 			msg: "测试",
-			err: ErrContains("invalid message type"),
+			err: errmatch.Contains("invalid message type"),
 		},
 		{
 			name: "empty content",
@@ -93,7 +125,7 @@ func TestBotClientSendText(t *testing.T) {
 			},
 			ctx: context.Background(),
 			msg: "测试",
-			err: ErrContains("empty content"),
+			err: errmatch.Contains("empty content"),
 		},
 	}
 
@@ -112,7 +144,7 @@ func TestBotClient_SendMarkdown(t *testing.T) {
 	if testing.Verbose() {
 		logger = slog.Default()
 	} else {
-		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		logger = NopLogger()
 	}
 
 	var mux http.ServeMux
@@ -135,8 +167,6 @@ func TestBotClient_SendMarkdown(t *testing.T) {
 	s := httptest.NewServer(&mux)
 	t.Cleanup(s.Close)
 
-	s.URL = `https://qyapi.weixin.qq.com`
-
 	testCasesMarkdown := []struct {
 		name   string          // 测试项目
 		client BotClient       // 客户端
@@ -168,17 +198,1877 @@ func TestBotClient_SendMarkdown(t *testing.T) {
 	}
 }
 
-// ErrContains 返回一个用于判断错误信息是否包含指定字符串的错误对象
-func ErrContains(s string) error {
-	return contains{s}
+func TestBotClient_SendNews(t *testing.T) {
+	// 按原始字段名解析请求体，校验 json 结构
+	var got map[string]any
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	article := Article{
+		Title:       "中秋节礼品领取",
+		Description: "今年中秋节公司有豪礼相送",
+		URL:         "https://www.qq.com",
+		PicURL:      "http://res.mail.qq.com/node/ww/wwopenmng/images/independent/doc/test_pic_msg1.png",
+	}
+
+	testCases := []struct {
+		name     string    // 测试项目
+		articles []Article // 图文
+		err      error     // 预期错误
+	}{
+		{
+			name:     "normal",
+			articles: []Article{article},
+			err:      nil,
+		},
+		{
+			name:     "empty articles",
+			articles: nil,
+			err:      errmatch.Contains("图文数量错误"),
+		},
+		{
+			name:     "too many articles",
+			articles: []Article{article, article, article, article, article, article, article, article, article},
+			err:      errmatch.Contains("图文数量错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := client.SendNews(context.Background(), tc.articles)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+
+			expect := map[string]any{
+				"msgtype": "news",
+				"news": map[string]any{
+					"articles": []any{
+						map[string]any{
+							"title":       article.Title,
+							"description": article.Description,
+							"url":         article.URL,
+							"picurl":      article.PicURL,
+						},
+					},
+				},
+			}
+			if !reflect.DeepEqual(got, expect) {
+				t.Fatalf("expect %v, got %v", expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendRaw(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":45009,"errmsg":"api freq out of limit"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	resp, err := client.SendRaw(context.Background(), Message{
+		MsgType: MessageTypeText,
+		Text:    &TextMessage{Content: "测试"},
+	})
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 45009 {
+		t.Fatalf("expect APIError 45009, got %v", err)
+	}
+	if resp.ErrCode != 45009 || resp.ErrMsg != "api freq out of limit" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestBotClient_Retry(t *testing.T) {
+	// 每个 key 前两次请求返回限流
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		mu.Lock()
+		counts[key]++
+		n := counts[key]
+		mu.Unlock()
+
+		if n <= 2 && key == "status_429" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if n <= 2 {
+			w.Write([]byte(`{"errcode":45009,"errmsg":"api freq out of limit"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name  string          // 测试项目
+		key   string          // 机器人令牌
+		retry RetryConfig     // 重试配置
+		ctx   context.Context // ctx 对象
+		err   error           // 预期错误
+	}{
+		{
+			name:  "no retry",
+			key:   "no_retry",
+			retry: RetryConfig{},
+			ctx:   context.Background(),
+			err:   APIError{Code: 45009, Message: "api freq out of limit"},
+		},
+		{
+			name:  "retry exhausted",
+			key:   "retry_exhausted",
+			retry: RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			ctx:   context.Background(),
+			err:   APIError{Code: 45009, Message: "api freq out of limit"},
+		},
+		{
+			name:  "retry succeeded",
+			key:   "retry_succeeded",
+			retry: RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			ctx:   context.Background(),
+			err:   nil,
+		},
+		{
+			name:  "status 429",
+			key:   "status_429",
+			retry: RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			ctx:   context.Background(),
+			err:   nil,
+		},
+		{
+			name:  "canceled",
+			key:   "canceled",
+			retry: RetryConfig{MaxAttempts: 3, BaseDelay: time.Hour},
+			ctx:   canceled,
+			err:   context.Canceled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.Key = tc.key
+			client.Retry = tc.retry
+			err := client.SendText(tc.ctx, "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendWithResult(t *testing.T) {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		mu.Lock()
+		counts[key]++
+		n := counts[key]
+		mu.Unlock()
+
+		if key == "bad_request" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if n <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name   string      // 测试项目
+		key    string      // 机器人令牌
+		retry  RetryConfig // 重试配置
+		expect SendResult  // 预期结果，不比较 Duration
+		err    bool        // 是否预期错误
+	}{
+		{
+			name:   "retry succeeded",
+			key:    "retry_succeeded",
+			retry:  RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			expect: SendResult{Attempts: 3, StatusCode: http.StatusOK, Response: SendResponse{ErrCode: 0, ErrMsg: "ok"}},
+		},
+		{
+			name:   "retry exhausted",
+			key:    "retry_exhausted",
+			retry:  RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			expect: SendResult{Attempts: 2, StatusCode: http.StatusTooManyRequests},
+			err:    true,
+		},
+		{
+			name:   "bad request",
+			key:    "bad_request",
+			retry:  RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			expect: SendResult{Attempts: 1, StatusCode: http.StatusBadRequest},
+			err:    true,
+		},
+		{
+			name:   "need token",
+			key:    "",
+			expect: SendResult{},
+			err:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.Key = tc.key
+			client.Retry = tc.retry
+			res, err := client.SendWithResult(context.Background(), Message{
+				MsgType: MessageTypeText,
+				Text:    &TextMessage{Content: "测试"},
+			})
+			if (err != nil) != tc.err {
+				t.Fatalf("expect error %v, got %v", tc.err, err)
+			}
+			if tc.expect.Attempts > 0 && res.Duration <= 0 {
+				t.Fatalf("expect positive duration, got %v", res.Duration)
+			}
+			res.Duration = 0
+			if res != tc.expect {
+				t.Fatalf("expect %+v, got %+v", tc.expect, res)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendTextMention(t *testing.T) {
+	var got Message
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendTextMention(context.Background(), "测试", []string{"wangqing", MentionAll}, []string{"13800001111"})
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if got.Text == nil {
+		t.Fatalf("expect text message, got %+v", got)
+	}
+	if !slices.Equal(got.Text.MentionedList, []string{"wangqing", "@all"}) {
+		t.Fatalf("unexpected mentioned_list: %v", got.Text.MentionedList)
+	}
+	if !slices.Equal(got.Text.MentionedMobileList, []string{"13800001111"}) {
+		t.Fatalf("unexpected mentioned_mobile_list: %v", got.Text.MentionedMobileList)
+	}
+
+	got = Message{}
+	err = client.SendTextMentionAll(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if got.Text == nil || !slices.Equal(got.Text.MentionedList, []string{"@all"}) {
+		t.Fatalf("unexpected message: %+v", got)
+	}
+}
+
+func TestBotClient_SendTextMentionByName(t *testing.T) {
+	var got Message
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	errNotFound := errors.New("not found")
+	directory := map[string]string{"王青": "wangqing", "李雷": "lilei"}
+	resolver := func(ctx context.Context, name string) (string, error) {
+		id, ok := directory[name]
+		if !ok {
+			return "", errNotFound
+		}
+		return id, nil
+	}
+
+	testCases := []struct {
+		name     string                                                 // 测试项目
+		resolver func(ctx context.Context, name string) (string, error) // 成员查找函数
+		names    []string                                               // 成员名称
+		want     []string                                               // 预期 mentioned_list
+		err      error                                                  // 预期错误
+	}{
+		{
+			name:     "normal",
+			resolver: resolver,
+			names:    []string{"王青", MentionAll, "李雷"},
+			want:     []string{"wangqing", "@all", "lilei"},
+			err:      nil,
+		},
+		{
+			name:     "not found",
+			resolver: resolver,
+			names:    []string{"王青", "韩梅梅"},
+			err:      errNotFound,
+		},
+		{
+			name:     "need resolver",
+			resolver: nil,
+			names:    []string{"王青"},
+			err:      ErrNeedMentionResolver,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = Message{}
+			client := base
+			client.MentionResolver = tc.resolver
+			err := client.SendTextMentionByName(context.Background(), "测试", tc.names)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+			if got.Text == nil || !slices.Equal(got.Text.MentionedList, tc.want) {
+				t.Fatalf("expect %v, got %+v", tc.want, got.Text)
+			}
+		})
+	}
+}
+
+func TestBotClient_ContentTooLong(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 不应发送任何请求
+	client := BotClient{
+		Client:  &http.Client{Transport: errTransport{t}},
+		Logger:  logger,
+		BaseURL: "http://example.com",
+		Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+	}
+
+	err := client.SendText(context.Background(), strings.Repeat("测", 683))
+	if !errors.Is(err, ErrContentTooLong) {
+		t.Fatalf("expect %v, got %v", ErrContentTooLong, err)
+	}
+	err = client.SendMarkdown(context.Background(), strings.Repeat("a", 4097))
+	if !errors.Is(err, ErrContentTooLong) {
+		t.Fatalf("expect %v, got %v", ErrContentTooLong, err)
+	}
+	err = client.SendMarkdownV2(context.Background(), strings.Repeat("a", 4097))
+	if !errors.Is(err, ErrContentTooLong) {
+		t.Fatalf("expect %v, got %v", ErrContentTooLong, err)
+	}
+}
+
+// 不允许发送请求的 http.RoundTripper
+type errTransport struct{ t *testing.T }
+
+func (e errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	e.t.Fatal("unexpected request")
+	return nil, errors.New("unexpected request")
+}
+
+func TestBotClient_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		<-done
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+	t.Cleanup(func() { close(done) })
+
+	client.Timeout = 10 * time.Millisecond
+
+	err := client.SendText(context.Background(), "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// ctx 自带的截止时间优先
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	client.Timeout = time.Hour
+	err = client.SendText(ctx, "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestBotClient_LogBody(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	for _, logBody := range []bool{true, false} {
+		var buf bytes.Buffer
+		client := BotClient{
+			Client:  s.Client(),
+			Logger:  slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			BaseURL: s.URL,
+			Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+			LogBody: logBody,
+		}
+		err := client.SendText(context.Background(), "敏感内容")
+		if err != nil {
+			t.Fatalf("expect nil, got %v", err)
+		}
+
+		logs := buf.String()
+		if strings.Contains(logs, "ee556a46-a3a7-4978-a186-7e3181f29da9") {
+			t.Fatalf("token should be redacted: %s", logs)
+		}
+		if strings.Contains(logs, "敏感内容") != logBody {
+			t.Fatalf("LogBody is %v, got logs: %s", logBody, logs)
+		}
+		if strings.Contains(logs, "***") != logBody {
+			t.Fatalf("LogBody is %v, got logs: %s", logBody, logs)
+		}
+	}
+}
+
+func TestBotClient_SendMarkdownV2(t *testing.T) {
+	var got []byte
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendMarkdownV2(context.Background(), "| 姓名 | 职位 |\n| :--- | :---: |\n| 张三 | 工程师 |")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	expect := `{"msgtype":"markdown_v2","markdown_v2":{"content":"| 姓名 | 职位 |\n| :--- | :---: |\n| 张三 | 工程师 |"}}`
+	if string(got) != expect {
+		t.Fatalf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestBotClient_Transport(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.Header.Get("X-Trace-Id") != "trace" {
+			w.Write([]byte(`{"errcode":40001,"errmsg":"missing trace id"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	client := BotClient{
+		Logger:  logger,
+		BaseURL: s.URL,
+		Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r.Header.Set("X-Trace-Id", "trace")
+			return s.Client().Transport.RoundTrip(r)
+		}),
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+}
+
+// 函数形式的 http.RoundTripper
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestBotClient_UnexpectedContentType(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<html><body>404 page not found</body></html>`))
+		w.Write([]byte(strings.Repeat("-", 1024)))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrUnexpectedContentType) {
+		t.Fatalf("expect %v, got %v", ErrUnexpectedContentType, err)
+	}
+	if !errors.Is(errmatch.Contains("404 page not found"), err) {
+		t.Fatalf("expect error contains body, got %v", err)
+	}
+	if len(err.Error()) > 2*maxSnippetBytes {
+		t.Fatalf("error too long: %d", len(err.Error()))
+	}
 }
 
-type contains struct{ string }
+func TestBotClient_WebhookPath(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /proxy/wx/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	client.WebhookPath = "/proxy/wx/webhook/send"
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
 
-func (e contains) Error() string {
-	return fmt.Sprintf("err should contains %q", e.string)
+	client.WebhookPath = ""
+	err = client.SendText(context.Background(), "测试")
+	if !errors.Is(errmatch.Contains("404"), err) {
+		t.Fatalf("expect 404 error, got %v", err)
+	}
 }
 
-func (e contains) Is(err error) bool {
-	return err != nil && strings.Contains(err.Error(), e.string)
+func TestBotClient_IdempotencyHeader(t *testing.T) {
+	// 第一次请求返回限流，记录每次请求的幂等请求头
+	var keys []string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if len(keys)%2 == 1 {
+			w.Write([]byte(`{"errcode":45009,"errmsg":"api freq out of limit"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	client.Retry = RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	client.IdempotencyHeader = "Idempotency-Key"
+	for range 2 {
+		err := client.SendText(context.Background(), "测试")
+		if err != nil {
+			t.Fatalf("expect nil, got %v", err)
+		}
+	}
+
+	if len(keys) != 4 {
+		t.Fatalf("expect 4 requests, got %d", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("expect same key across retries, got %q and %q", keys[0], keys[1])
+	}
+	if keys[2] == keys[0] || keys[2] != keys[3] {
+		t.Fatalf("expect new key for a new message, got %q", keys)
+	}
+}
+
+func TestBotClient_Limiter(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	client.Limiter = rate.NewLimiter(rate.Every(time.Hour), 1)
+
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	err = client.SendText(ctx, "测试")
+	if err != context.Canceled {
+		t.Fatalf("expect %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestBotClient_OnSend(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	type outcome struct {
+		msgType  string
+		duration time.Duration
+		err      error
+	}
+	var outcomes []outcome
+	client.OnSend = func(msgType string, duration time.Duration, err error) {
+		outcomes = append(outcomes, outcome{msgType, duration, err})
+	}
+
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	client.Key = ""
+	err = client.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrNeedToken) {
+		t.Fatalf("expect %v, got %v", ErrNeedToken, err)
+	}
+
+	if len(outcomes) != 2 {
+		t.Fatalf("expect 2 outcomes, got %d", len(outcomes))
+	}
+	if o := outcomes[0]; o.msgType != MessageTypeText || o.duration <= 0 || o.err != nil {
+		t.Fatalf("unexpected outcome: %+v", o)
+	}
+	if o := outcomes[1]; o.msgType != MessageTypeText || o.duration != 0 || o.err != ErrNeedToken {
+		t.Fatalf("unexpected outcome: %+v", o)
+	}
+
+	// 耗时只统计请求本身，不包含限流等待
+	const wait = 300 * time.Millisecond
+	client.Key = "ee556a46-a3a7-4978-a186-7e3181f29da9"
+	client.Limiter = rate.NewLimiter(rate.Every(wait), 1)
+	client.Limiter.Allow()
+	start := time.Now()
+	err = client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if wall := time.Since(start); wall < wait/2 {
+		t.Fatalf("expect limiter wait, got %v", wall)
+	}
+	if o := outcomes[2]; o.duration <= 0 || o.duration >= wait/2 {
+		t.Fatalf("expect duration of the request only, got %v", o.duration)
+	}
+}
+
+func TestBotClient_UserAgent(t *testing.T) {
+	var got string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	base, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name      string // 测试项目
+		userAgent string // User-Agent
+		expect    string // 预期 User-Agent
+	}{
+		{name: "default", userAgent: "", expect: "kvii-bot/1.0"},
+		{name: "custom", userAgent: "alert-service/2.3", expect: "alert-service/2.3"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.UserAgent = tc.userAgent
+			err := client.SendText(context.Background(), "测试")
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+			if got != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_DryRun(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 不应发送任何请求
+	client := BotClient{
+		Client:  &http.Client{Transport: errTransport{t}},
+		Logger:  logger,
+		BaseURL: "http://example.com",
+		Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+		DryRun:  true,
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	err = client.SendText(context.Background(), strings.Repeat("a", MaxTextBytes+1))
+	if !errors.Is(err, ErrContentTooLong) {
+		t.Fatalf("expect %v, got %v", ErrContentTooLong, err)
+	}
+
+	client.Key = ""
+	err = client.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrNeedToken) {
+		t.Fatalf("expect %v, got %v", ErrNeedToken, err)
+	}
+}
+
+func TestDefaultPooledClient(t *testing.T) {
+	c := DefaultPooledClient()
+	tr, ok := c.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("unexpected transport: %T", c.Transport)
+	}
+	if tr.MaxIdleConnsPerHost != 20 || tr.IdleConnTimeout != 90*time.Second {
+		t.Fatalf("unexpected transport config: %d %v", tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+	if tr == http.DefaultTransport {
+		t.Fatalf("should not modify http.DefaultTransport")
+	}
+}
+
+func TestBotClient_LogAttrsFromContext(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	type traceKey struct{}
+	var buf bytes.Buffer
+	client := BotClient{
+		Client:  s.Client(),
+		Logger:  slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		BaseURL: s.URL,
+		Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+		LogBody: true,
+		LogAttrsFromContext: func(ctx context.Context) []slog.Attr {
+			id, _ := ctx.Value(traceKey{}).(string)
+			return []slog.Attr{slog.String("trace-id", id)}
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "abc123")
+	err := client.SendText(ctx, "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expect multiple log lines, got %q", lines)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "trace-id=abc123") {
+			t.Fatalf("expect trace-id in every line, got %q", line)
+		}
+	}
+}
+
+func TestBotClient_HTTPError(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("too many requests"))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	err := client.SendText(context.Background(), "测试")
+
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expect HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expect status %d, got %d", http.StatusTooManyRequests, httpErr.StatusCode)
+	}
+	if v := httpErr.Header.Get("Retry-After"); v != "30" {
+		t.Fatalf("expect Retry-After 30, got %q", v)
+	}
+	if string(httpErr.Body) != "too many requests" {
+		t.Fatalf("unexpected body: %s", httpErr.Body)
+	}
+}
+
+func TestBotClient_ProxyURL(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 作为代理的测试服务器，普通代理请求的 URL 为完整地址
+	var got string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.String()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	t.Cleanup(proxy.Close)
+
+	testCases := []struct {
+		name   string    // 测试项目
+		client BotClient // 客户端
+		err    error     // 预期错误
+	}{
+		{
+			name:   "normal",
+			client: BotClient{ProxyURL: proxy.URL},
+			err:    nil,
+		},
+		{
+			name:   "malformed",
+			client: BotClient{ProxyURL: "://proxy"},
+			err:    errmatch.Contains("代理地址错误"),
+		},
+		{
+			name:   "missing host",
+			client: BotClient{ProxyURL: "proxy.example.com"},
+			err:    errmatch.Contains("代理地址错误"),
+		},
+		{
+			name:   "conflict",
+			client: BotClient{ProxyURL: proxy.URL, Client: proxy.Client()},
+			err:    ErrProxyConflict,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = ""
+			tc.client.Logger = logger
+			tc.client.BaseURL = "http://bot.example.com"
+			tc.client.Key = "ee556a46-a3a7-4978-a186-7e3181f29da9"
+			err := tc.client.SendText(context.Background(), "测试")
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err == nil && !strings.HasPrefix(got, "http://bot.example.com/") {
+				t.Fatalf("expect request through proxy, got %q", got)
+			}
+		})
+	}
+}
+
+func TestBotClient_WebhookInvalid(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+	}))
+	t.Cleanup(s.Close)
+
+	c := BotClient{
+		Logger:  logger,
+		BaseURL: s.URL,
+		Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+	}
+	err := c.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrWebhookInvalid) {
+		t.Fatalf("expect %v, got %v", ErrWebhookInvalid, err)
+	}
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "invalid webhook url" {
+		t.Fatalf("expect APIError with original message, got %v", err)
+	}
+	if errors.Is(APIError{Code: codeFreqOutOfLimit}, ErrWebhookInvalid) {
+		t.Fatal("expect other codes not to match ErrWebhookInvalid")
+	}
+}
+
+func TestBotClient_SlowBody(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 响应体每隔一段时间写入一个字节，且不感知 ctx
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			for {
+				time.Sleep(5 * time.Millisecond)
+				if _, err := pw.Write([]byte(" ")); err != nil {
+					return
+				}
+			}
+		}()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       pr,
+		}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := BotClient{
+		Logger:    logger,
+		BaseURL:   "http://bot.example.com",
+		Key:       "ee556a46-a3a7-4978-a186-7e3181f29da9",
+		Transport: transport,
+	}
+	err := client.SendText(ctx, "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestBotClient_Marshal(t *testing.T) {
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	var marshaled, unmarshaled int
+	client.Marshal = func(v any) ([]byte, error) {
+		marshaled++
+		return json.Marshal(v)
+	}
+	client.Unmarshal = func(data []byte, v any) error {
+		unmarshaled++
+		return json.Unmarshal(data, v)
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if marshaled != 1 || unmarshaled != 1 {
+		t.Fatalf("expect custom encoder to be used once, got marshal %d, unmarshal %d", marshaled, unmarshaled)
+	}
+}
+
+func TestBotClient_Headers(t *testing.T) {
+	var got http.Header
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	client.Headers = http.Header{
+		"X-Trace-Id":   {"trace"},
+		"content-type": {"text/plain"},
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if v := got.Get("X-Trace-Id"); v != "trace" {
+		t.Fatalf("expect X-Trace-Id %q, got %q", "trace", v)
+	}
+	if v := got.Get("Content-Type"); v != "application/json" {
+		t.Fatalf("expect Content-Type %q, got %q", "application/json", v)
+	}
+}
+
+func TestBotClient_SendJSON(t *testing.T) {
+	var got []byte
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if bytes.Contains(got, []byte("unknown")) {
+			w.Write([]byte(`{"errcode":40008,"errmsg":"invalid message type"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	testCases := []struct {
+		name string          // 测试项目
+		raw  json.RawMessage // 请求体
+		err  error           // 预期错误
+	}{
+		{
+			name: "normal",
+			raw:  json.RawMessage(`{"msgtype": "text", "text": {"content": "测试"}}`),
+			err:  nil,
+		},
+		{
+			name: "invalid json",
+			raw:  json.RawMessage(`{"msgtype":`),
+			err:  errmatch.Contains("json 格式错误"),
+		},
+		{
+			name: "not object",
+			raw:  json.RawMessage(`null`),
+			err:  errmatch.Contains("json 格式错误"),
+		},
+		{
+			name: "non-string type",
+			raw:  json.RawMessage(`{"msgtype": 1}`),
+			err:  errmatch.Contains("msgtype 需要为非空字符串"),
+		},
+		{
+			name: "missing type",
+			raw:  json.RawMessage(`{"content": {}}`),
+			err:  errmatch.Contains("msgtype 需要为非空字符串"),
+		},
+		{
+			name: "api error",
+			raw:  json.RawMessage(`{"msgtype":"unknown"}`),
+			err:  APIError{Code: 40008, Message: "invalid message type"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := client.SendJSON(context.Background(), tc.raw)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err == nil && string(got) != string(tc.raw) {
+				t.Fatalf("expect %s, got %s", tc.raw, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_ContentTypeVariants(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	for _, contentType := range []string{
+		"application/json",
+		"application/json; charset=utf-8",
+		"Application/JSON",
+		"text/json",
+		"TEXT/JSON; charset=UTF-8",
+	} {
+		t.Run(contentType, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", contentType)
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+			}))
+			t.Cleanup(s.Close)
+
+			client := BotClient{
+				Client:  s.Client(),
+				Logger:  logger,
+				BaseURL: s.URL,
+				Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+			}
+			err := client.SendText(context.Background(), "测试")
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendTextf(t *testing.T) {
+	var got Message
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	err := client.SendTextf(context.Background(), "服务 %s 异常, 错误数 %d", "api", 3)
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if got.Text == nil || got.Text.Content != "服务 api 异常, 错误数 3" {
+		t.Fatalf("unexpected text message: %+v", got.Text)
+	}
+
+	// 格式化后超过长度上限
+	err = client.SendTextf(context.Background(), "%s", strings.Repeat("a", MaxTextBytes+1))
+	if !errors.Is(err, ErrContentTooLong) {
+		t.Fatalf("expect %v, got %v", ErrContentTooLong, err)
+	}
+}
+
+func TestBotClient_RequestURL(t *testing.T) {
+	testCases := []struct {
+		name   string    // 测试项目
+		client BotClient // 客户端
+		want   string    // 预期地址
+		err    error     // 预期错误
+	}{
+		{
+			name:   "default",
+			client: BotClient{Key: "ee556a46-a3a7-4978-a186-7e3181f29da9"},
+			want:   "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=***",
+		},
+		{
+			name:   "custom",
+			client: BotClient{BaseURL: "http://gateway.example.com", WebhookPath: "/wx/send", Key: "xxx"},
+			want:   "http://gateway.example.com/wx/send?key=***",
+		},
+		{
+			name:   "need token",
+			client: BotClient{},
+			err:    ErrNeedToken,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.client.RequestURL()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if got != tc.want {
+				t.Fatalf("expect %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_BeforeSend(t *testing.T) {
+	var got []byte
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	errAbort := errors.New("abort")
+	testCases := []struct {
+		name   string                                        // 测试项目
+		hook   func(ctx context.Context, msg *Message) error // 发送前钩子
+		expect string                                        // 预期请求体
+		err    error                                         // 预期错误
+	}{
+		{
+			name: "footer",
+			hook: func(ctx context.Context, msg *Message) error {
+				msg.Text.Content += "\n-- 来自监控系统"
+				return nil
+			},
+			expect: `{"msgtype":"text","text":{"content":"测试\n-- 来自监控系统"}}`,
+			err:    nil,
+		},
+		{
+			name: "abort",
+			hook: func(ctx context.Context, msg *Message) error {
+				return errAbort
+			},
+			err: errAbort,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			client := base
+			client.BeforeSend = tc.hook
+			err := client.SendText(context.Background(), "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if string(got) != tc.expect {
+				t.Fatalf("expect %s, got %s", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_Compress(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 路径 /reject 下的服务端不接受压缩请求，路径 /invalid 下的服务端对压缩请求返回与压缩无关的 400
+	var encodings []string
+	var bodies []string
+	handler := func(status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			encoding := r.Header.Get("Content-Encoding")
+			encodings = append(encodings, encoding)
+			if encoding == "gzip" && status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			var body io.Reader = r.Body
+			if encoding == "gzip" {
+				zr, err := gzip.NewReader(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				body = zr
+			}
+			bs, _ := io.ReadAll(body)
+			bodies = append(bodies, string(bs))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}
+	}
+	var mux http.ServeMux
+	mux.Handle("POST /accept/cgi-bin/webhook/send", handler(http.StatusOK))
+	mux.Handle("POST /reject/cgi-bin/webhook/send", handler(http.StatusUnsupportedMediaType))
+	mux.Handle("POST /invalid/cgi-bin/webhook/send", handler(http.StatusBadRequest))
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	large := strings.Repeat("a", 2*compressThreshold)
+	testCases := []struct {
+		name      string   // 测试项目
+		baseURL   string   // 基础地址
+		msg       string   // 信息内容
+		encodings []string // 预期每次请求的 Content-Encoding
+		fail      bool     // 预期发送失败
+	}{
+		{name: "small", baseURL: s.URL + "/accept", msg: "测试", encodings: []string{""}},
+		{name: "large", baseURL: s.URL + "/accept", msg: large, encodings: []string{"gzip"}},
+		{name: "fallback", baseURL: s.URL + "/reject", msg: large, encodings: []string{"gzip", ""}},
+		{name: "bad request", baseURL: s.URL + "/invalid", msg: large, encodings: []string{"gzip"}, fail: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encodings, bodies = nil, nil
+			client := BotClient{
+				Client:   s.Client(),
+				Logger:   logger,
+				BaseURL:  tc.baseURL,
+				Key:      "ee556a46-a3a7-4978-a186-7e3181f29da9",
+				Compress: true,
+				Limiter:  rate.NewLimiter(rate.Every(time.Hour), 2),
+			}
+			res, err := client.SendWithResult(context.Background(), Message{
+				MsgType:  MessageTypeMarkdown,
+				Markdown: &MarkdownMessage{Content: tc.msg},
+			})
+			if !slices.Equal(encodings, tc.encodings) {
+				t.Fatalf("expect encodings %q, got %q", tc.encodings, encodings)
+			}
+			if res.Attempts != len(tc.encodings) {
+				t.Fatalf("expect %d attempts, got %d", len(tc.encodings), res.Attempts)
+			}
+			// 每次请求都需要等待限流器放行
+			if used := 2 - int(math.Round(client.Limiter.Tokens())); used != len(tc.encodings) {
+				t.Fatalf("expect %d limiter tokens used, got %d", len(tc.encodings), used)
+			}
+			if tc.fail {
+				if err == nil {
+					t.Fatal("expect error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+			if len(bodies) != 1 || !strings.Contains(bodies[0], tc.msg) {
+				t.Fatalf("unexpected bodies: %d", len(bodies))
+			}
+		})
+	}
+}
+
+func TestBotClient_SuccessFunc(t *testing.T) {
+	// 网关将原始响应包装在 result 字段中，并以 ok 字段表示成功
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		if r.Header.Get("X-Fail") != "" {
+			w.Write([]byte(`{"ok":false,"result":null}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+
+	errGateway := errors.New("gateway error")
+	success := func(status int, body []byte) error {
+		var envelope struct {
+			OK bool `json:"ok"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil || !envelope.OK {
+			return errGateway
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name    string                              // 测试项目
+		fail    bool                                // 是否让网关返回失败
+		success func(status int, body []byte) error // 响应检查函数
+		err     error                               // 预期错误
+	}{
+		{name: "custom success", fail: false, success: success, err: nil},
+		{name: "custom failure", fail: true, success: success, err: errGateway},
+		{name: "default", fail: false, success: nil, err: ErrUnexpectedContentType},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.SuccessFunc = tc.success
+			if tc.fail {
+				client.Headers = http.Header{"X-Fail": {"1"}}
+			}
+			err := client.SendText(context.Background(), "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestBotClient_EmptyContent(t *testing.T) {
+	var called bool
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":44004,"errmsg":"empty content"}`))
+	}))
+
+	tests := []struct {
+		name string
+		send func(ctx context.Context, msg string) error
+		msg  string
+	}{
+		{name: "text empty", send: client.SendText, msg: ""},
+		{name: "text whitespace", send: client.SendText, msg: " \n\t"},
+		{name: "markdown empty", send: client.SendMarkdown, msg: ""},
+		{name: "markdown whitespace", send: client.SendMarkdown, msg: "　 \r\n"},
+		{name: "markdown v2 whitespace", send: client.SendMarkdownV2, msg: "  "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			err := tt.send(context.Background(), tt.msg)
+			if !errors.Is(err, ErrEmptyContent) {
+				t.Fatalf("expect %v, got %v", ErrEmptyContent, err)
+			}
+			if called {
+				t.Fatal("expect no request")
+			}
+		})
+	}
+}
+
+func TestContextWithKey(t *testing.T) {
+	var got string
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("key")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	tests := []struct {
+		name   string
+		key    string          // 客户端的令牌
+		ctx    context.Context // ctx 对象
+		expect string          // 预期使用的令牌
+		err    error           // 预期错误
+	}{
+		{name: "field", key: "field", ctx: context.Background(), expect: "field"},
+		{name: "override", key: "field", ctx: ContextWithKey(context.Background(), "tenant"), expect: "tenant"},
+		{name: "context only", ctx: ContextWithKey(context.Background(), "tenant"), expect: "tenant"},
+		{name: "empty override", key: "field", ctx: ContextWithKey(context.Background(), ""), expect: "field"},
+		{name: "none", ctx: context.Background(), err: ErrNeedToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = ""
+			client := base
+			client.Key = tt.key
+			err := client.SendText(tt.ctx, "测试")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expect %v, got %v", tt.err, err)
+			}
+			if got != tt.expect {
+				t.Fatalf("expect key %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_PreserveResponseBody(t *testing.T) {
+	const (
+		apiBody  = `{"errcode":40008,"errmsg":"invalid message type"}`
+		htmlBody = `<html>gateway</html>`
+	)
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("key") {
+		case "api":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(apiBody))
+		case "html":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(htmlBody))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}
+	})
+	base, _ := newTestServer(t, &mux)
+
+	tests := []struct {
+		name     string
+		key      string // 令牌，决定响应内容
+		preserve bool   // 是否保留响应内容
+		body     string // 预期的响应内容，为空表示不返回 HTTPError
+		err      error  // 预期包含的原错误
+	}{
+		{name: "api off", key: "api", err: APIError{Code: 40008, Message: "invalid message type"}},
+		{name: "api on", key: "api", preserve: true, body: apiBody, err: APIError{Code: 40008, Message: "invalid message type"}},
+		{name: "content type off", key: "html", err: ErrUnexpectedContentType},
+		{name: "content type on", key: "html", preserve: true, body: htmlBody, err: ErrUnexpectedContentType},
+		{name: "success on", key: "ok", preserve: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := base
+			client.Key = tt.key
+			client.PreserveResponseBody = tt.preserve
+			err := client.SendText(context.Background(), "测试")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expect %v, got %v", tt.err, err)
+			}
+
+			var httpErr HTTPError
+			ok := errors.As(err, &httpErr)
+			if ok != (tt.body != "") {
+				t.Fatalf("expect HTTPError %t, got %v", tt.body != "", err)
+			}
+			if ok {
+				if string(httpErr.Body) != tt.body {
+					t.Fatalf("expect body %q, got %q", tt.body, httpErr.Body)
+				}
+				if httpErr.StatusCode != http.StatusOK {
+					t.Fatalf("expect status %d, got %d", http.StatusOK, httpErr.StatusCode)
+				}
+				if err.Error() != httpErr.Err.Error() {
+					t.Fatalf("expect message %q, got %q", httpErr.Err.Error(), err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestBotClient_SendTextTimeout(t *testing.T) {
+	done := make(chan struct{})
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Slow") != "" {
+			<-done
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+	t.Cleanup(func() { close(done) })
+
+	err := client.SendTextTimeout(context.Background(), time.Second, "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+
+	client.Headers = http.Header{"X-Slow": {"1"}}
+	err = client.SendTextTimeout(context.Background(), time.Millisecond, "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// ctx 的截止时间更早时以 ctx 为准
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = client.SendTextTimeout(ctx, time.Hour, "测试")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expect parent deadline, waited %v", elapsed)
+	}
+}
+
+func TestBotClient_SendVoice(t *testing.T) {
+	var got Message
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	err := client.SendVoice(context.Background(), "MEDIA_ID")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if got.MsgType != MessageTypeVoice || got.Voice == nil || got.Voice.MediaID != "MEDIA_ID" {
+		t.Fatalf("unexpected message: %+v", got)
+	}
+
+	err = client.SendVoice(context.Background(), "")
+	if !errors.Is(err, ErrEmptyMediaID) {
+		t.Fatalf("expect %v, got %v", ErrEmptyMediaID, err)
+	}
+}
+
+// 以函数实现的 Doer
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBotClient_Doer(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	var got *http.Request
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		got = req
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"errcode":0,"errmsg":"ok"}`)),
+		}, nil
+	})
+
+	client := BotClient{
+		Doer:    doer,
+		Client:  &http.Client{Transport: doerTransport{}},
+		Logger:  logger,
+		BaseURL: "http://bot.example.com",
+		Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if got == nil || got.URL.Host != "bot.example.com" {
+		t.Fatalf("expect request to bot.example.com, got %v", got)
+	}
+
+	client.ProxyURL = "http://proxy.example.com"
+	client.Client = nil
+	err = client.SendText(context.Background(), "测试")
+	if !errors.Is(err, ErrProxyConflict) {
+		t.Fatalf("expect %v, got %v", ErrProxyConflict, err)
+	}
+}
+
+// 总是失败的传输层，用于确认 Doer 优先于 Client 使用
+type doerTransport struct{}
+
+func (doerTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("unexpected round trip")
+}
+
+func TestBotClient_DefaultMentions(t *testing.T) {
+	var got []json.RawMessage
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Text json.RawMessage `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+		got = append(got, msg.Text)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	client.DefaultMentionedList = []string{"oncall"}
+	client.DefaultMentionedMobileList = []string{"13800001111"}
+
+	testCases := []struct {
+		name   string       // 测试项目
+		send   func() error // 发送过程
+		expect []string     // 预期的 text 字段
+	}{
+		{
+			name:   "text",
+			send:   func() error { return client.SendText(context.Background(), "测试") },
+			expect: []string{`{"content":"测试","mentioned_list":["oncall"],"mentioned_mobile_list":["13800001111"]}`},
+		},
+		{
+			name:   "opt out",
+			send:   func() error { return client.SendText(WithoutDefaultMentions(context.Background()), "测试") },
+			expect: []string{`{"content":"测试"}`},
+		},
+		{
+			name:   "override",
+			send:   func() error { return client.SendTextMention(context.Background(), "测试", []string{"other"}, nil) },
+			expect: []string{`{"content":"测试","mentioned_list":["other"]}`},
+		},
+		{
+			name: "chunked",
+			send: func() error {
+				return client.SendTextChunked(context.Background(), strings.Repeat("a", MaxTextBytes)+"b")
+			},
+			expect: []string{
+				`{"content":"` + strings.Repeat("a", MaxTextBytes) + `","mentioned_list":["oncall"],"mentioned_mobile_list":["13800001111"]}`,
+				`{"content":"b"}`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			if err := tc.send(); err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+			if len(got) != len(tc.expect) {
+				t.Fatalf("expect %d requests, got %d", len(tc.expect), len(got))
+			}
+			for i := range got {
+				if string(got[i]) != tc.expect[i] {
+					t.Fatalf("expect %s, got %s", tc.expect[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBotClient_OnError(t *testing.T) {
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	var got []Message
+	var gotErr error
+	client.OnError = func(ctx context.Context, msg Message, err error) {
+		got = append(got, msg)
+		gotErr = err
+	}
+
+	err := client.Send(context.Background(), Message{
+		MsgType: MessageTypeText,
+		Text:    &TextMessage{Content: "测试"},
+	})
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status error, got %v", err)
+	}
+	if len(got) != 1 || got[0].MsgType != MessageTypeText || got[0].Text.Content != "测试" {
+		t.Fatalf("unexpected messages: %+v", got)
+	}
+	if !errors.As(gotErr, &httpErr) {
+		t.Fatalf("expect %v, got %v", err, gotErr)
+	}
+}
+
+func TestBotClient_SendAll(t *testing.T) {
+	var got []string
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		text := msg.Text.Content
+		got = append(got, text)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if text == "fail" {
+			w.Write([]byte(`{"errcode":40008,"errmsg":"invalid message type"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	messages := func(texts ...string) []Message {
+		var msgs []Message
+		for _, text := range texts {
+			msgs = append(msgs, Message{MsgType: MessageTypeText, Text: &TextMessage{Content: text}})
+		}
+		return msgs
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name   string          // 测试项目
+		ctx    context.Context // ctx 对象
+		msgs   []Message       // 信息
+		expect []string        // 预期依次收到的信息
+		err    error           // 预期错误
+	}{
+		{name: "empty", ctx: context.Background(), msgs: nil, expect: nil},
+		{name: "ordered", ctx: context.Background(), msgs: messages("1", "2", "3"), expect: []string{"1", "2", "3"}},
+		{
+			name:   "stop at first error",
+			ctx:    context.Background(),
+			msgs:   messages("1", "fail", "3"),
+			expect: []string{"1", "fail"},
+			err:    errmatch.Contains("第 2 条信息发送失败 (共 3 条)"),
+		},
+		{name: "canceled", ctx: canceled, msgs: messages("1"), expect: nil, err: context.Canceled},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := client.SendAll(tc.ctx, tc.msgs)
+			if !errors.Is(tc.err, err) && !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if !slices.Equal(got, tc.expect) {
+				t.Fatalf("expect %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBuildURL(t *testing.T) {
+	testCases := []struct {
+		name   string // 测试项目
+		base   string // 基础地址
+		path   string // 接口路径
+		key    string // 令牌
+		expect string // 预期地址
+		err    bool   // 是否预期错误
+	}{
+		{name: "default", key: "k", expect: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=k"},
+		{name: "trailing slash", base: "http://localhost/", key: "k", expect: "http://localhost/cgi-bin/webhook/send?key=k"},
+		{name: "base path", base: "http://localhost/proxy/", key: "k", expect: "http://localhost/proxy/cgi-bin/webhook/send?key=k"},
+		{name: "custom path", base: "http://localhost", path: "/gateway/send", key: "k", expect: "http://localhost/gateway/send?key=k"},
+		{name: "base query", base: "http://localhost?a=1", key: "k", expect: "http://localhost/cgi-bin/webhook/send?a=1&key=k"},
+		{name: "special key", key: "a b&c/=", expect: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=a+b%26c%2F%3D"},
+		{name: "malformed base", base: "://localhost", key: "k", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := buildURL(tc.base, tc.path, tc.key)
+			if (err != nil) != tc.err {
+				t.Fatalf("expect error %t, got %v", tc.err, err)
+			}
+			if got != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_MaxResponseBytes(t *testing.T) {
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.Header.Get("X-Huge") != "" {
+			w.Write([]byte(`{"padding":"` + strings.Repeat("a", 2<<20) + `"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	testCases := []struct {
+		name    string      // 测试项目
+		limit   int64       // 响应大小上限
+		headers http.Header // 请求头
+		err     error       // 预期错误
+	}{
+		{name: "normal", limit: 0, err: nil},
+		{name: "default limit", limit: 0, headers: http.Header{"X-Huge": {"1"}}, err: ErrResponseTooLarge},
+		{name: "custom limit", limit: 8, err: ErrResponseTooLarge},
+		{name: "raised limit", limit: 4 << 20, headers: http.Header{"X-Huge": {"1"}}, err: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := base
+			client.Headers = tc.headers
+			client.MaxResponseBytes = tc.limit
+			err := client.SendText(context.Background(), "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestBotClient_RedactRequestError(t *testing.T) {
+	var buf bytes.Buffer
+	client := BotClient{
+		Transport: doerTransport{},
+		Logger:    slog.New(slog.NewTextHandler(&buf, nil)),
+		BaseURL:   "http://bot.example.com",
+		Key:       "ee556a46-a3a7-4978-a186-7e3181f29da9",
+	}
+	err := client.SendText(context.Background(), "测试")
+	if err == nil {
+		t.Fatal("expect error, got nil")
+	}
+	var ue *url.Error
+	if !errors.As(err, &ue) {
+		t.Fatalf("expect *url.Error, got %T", err)
+	}
+	if strings.Contains(err.Error(), "ee556a46-a3a7-4978-a186-7e3181f29da9") {
+		t.Fatalf("token should be redacted: %v", err)
+	}
+	if logs := buf.String(); strings.Contains(logs, "ee556a46-a3a7-4978-a186-7e3181f29da9") {
+		t.Fatalf("token should be redacted: %s", logs)
+	}
 }
@@ -0,0 +1,31 @@
+package wx
+
+import (
+	"context"
+	"time"
+
+	"github.com/kvii/bot/internal/backoff"
+)
+
+// 时间来源。零值使用系统时间。
+// 测试时可替换为固定的时间与立即返回的等待，使重试等待与时间戳可预测。
+type clock struct {
+	now   func() time.Time                                 // 当前时间。为空时使用 time.Now
+	sleep func(ctx context.Context, d time.Duration) error // 等待 d 时长。为空时使用 backoff.Sleep
+}
+
+// 方法返回当前时间。
+func (c BotClient) now() time.Time {
+	if c.clock.now == nil {
+		return time.Now()
+	}
+	return c.clock.now()
+}
+
+// 方法等待 d 时长。ctx 先结束时返回 ctx.Err()。
+func (c BotClient) sleep(ctx context.Context, d time.Duration) error {
+	if c.clock.sleep == nil {
+		return backoff.Sleep(ctx, d)
+	}
+	return c.clock.sleep(ctx, d)
+}
@@ -0,0 +1,66 @@
+package wx
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBotClient_Clock(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	// 前三次请求返回限流
+	var n int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if n <= 3 {
+			w.Write([]byte(`{"errcode":45009,"errmsg":"api freq out of limit"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	t.Cleanup(s.Close)
+
+	now := time.Date(2024, 5, 21, 12, 0, 0, 0, time.UTC)
+	var delays []time.Duration
+	c, err := NewBotClient("ee556a46-a3a7-4978-a186-7e3181f29da9",
+		WithHTTPClient(s.Client()),
+		WithLogger(logger),
+		WithBaseURL(s.URL),
+		withClock(
+			func() time.Time { return now },
+			func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			},
+		),
+	)
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	c.Retry = RetryConfig{MaxAttempts: 4, BaseDelay: time.Minute}
+	c.Tracker = new(SendTracker)
+
+	err = c.SendText(context.Background(), "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	expect := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute}
+	if !slices.Equal(delays, expect) {
+		t.Fatalf("expect delays %v, got %v", expect, delays)
+	}
+	if got := c.LastSentAt(); !got.Equal(now) {
+		t.Fatalf("expect last sent at %v, got %v", now, got)
+	}
+}
@@ -0,0 +1,46 @@
+package wx
+
+import "context"
+
+// ctx 中保存令牌使用的键
+type keyContextKey struct{}
+
+// 函数返回保存了令牌的 ctx。
+// 使用该 ctx 发送信息或上传文件时，ctx 中的令牌优先于客户端的 Key 字段，
+// 可用于多租户场景下由同一个客户端为不同租户发送信息。key 为空时不覆盖。
+// [MultiClient] 与 [FailoverClient] 会忽略 ctx 中的令牌，始终使用 Keys 中的令牌；
+// [AsyncClient] 在信息放入缓冲区时读取 ctx 中的令牌，后台发送时使用。
+func ContextWithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyContextKey{}, key)
+}
+
+// 函数返回 ctx 中保存的令牌。未保存或令牌为空时 ok 为 false。
+func KeyFromContext(ctx context.Context) (key string, ok bool) {
+	key, _ = ctx.Value(keyContextKey{}).(string)
+	return key, key != ""
+}
+
+// 方法返回使用 ctx 中的令牌覆盖 Key 字段后的客户端。
+func (c BotClient) withContextKey(ctx context.Context) BotClient {
+	if key, ok := KeyFromContext(ctx); ok {
+		c.Key = key
+	}
+	return c
+}
+
+// 函数返回不含令牌的 ctx，其他值与取消信号保持不变。
+func withoutContextKey(ctx context.Context) context.Context {
+	if _, ok := KeyFromContext(ctx); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, keyContextKey{}, "")
+}
+
+// ctx 中标记不使用默认提醒成员的键
+type noDefaultMentionsKey struct{}
+
+// 函数返回不使用默认提醒成员的 ctx。
+// 使用该 ctx 发送信息时，不会自动提醒 DefaultMentionedList 与 DefaultMentionedMobileList 中的成员。
+func WithoutDefaultMentions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noDefaultMentionsKey{}, true)
+}
@@ -0,0 +1,41 @@
+package wx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSendText(t *testing.T) {
+	var got string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("key")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	// 将请求转发到测试服务器
+	target, _ := url.Parse(s.URL)
+	SetDefaultHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r.URL.Scheme = target.Scheme
+			r.URL.Host = target.Host
+			return s.Client().Transport.RoundTrip(r)
+		}),
+	})
+	t.Cleanup(func() { SetDefaultHTTPClient(nil) })
+
+	err := SendText(context.Background(), "ee556a46-a3a7-4978-a186-7e3181f29da9", "测试")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if got != "ee556a46-a3a7-4978-a186-7e3181f29da9" {
+		t.Fatalf("expect %q, got %q", "ee556a46-a3a7-4978-a186-7e3181f29da9", got)
+	}
+}
@@ -0,0 +1,71 @@
+package wx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// 故障转移客户端。
+// 同一个逻辑机器人配置多个令牌，按顺序尝试发送：
+// 令牌失效 ([ErrWebhookInvalid]) 或服务端错误 (5xx) 时尝试下一个令牌，其他错误直接返回。
+type FailoverClient struct {
+	BotClient BotClient // 客户端配置。Key 字段会被 Keys 中的值替换。
+	Keys      []string  // 机器人令牌列表，按顺序尝试。
+}
+
+// 方法发送文本信息。
+func (f FailoverClient) SendText(ctx context.Context, msg string) error {
+	return f.each(ctx, func(ctx context.Context, c BotClient) error {
+		return c.SendText(ctx, msg)
+	})
+}
+
+// 方法发送信息。
+func (f FailoverClient) Send(ctx context.Context, msg Message) error {
+	return f.each(ctx, func(ctx context.Context, c BotClient) error {
+		return c.Send(ctx, msg)
+	})
+}
+
+// 方法依次使用每个令牌对应的客户端执行 f，直到成功或遇到不需要转移的错误。
+// 所有令牌都失败时，返回合并后的错误。
+// ctx 中由 [ContextWithKey] 保存的令牌会被移除，以免覆盖 Keys 中的令牌。
+func (f FailoverClient) each(ctx context.Context, fn func(ctx context.Context, c BotClient) error) error {
+	ctx = withoutContextKey(ctx)
+	if len(f.Keys) == 0 {
+		return ErrNeedToken
+	}
+
+	var errs []error
+	for i, key := range f.Keys {
+		c := f.BotClient
+		c.Key = key
+
+		err := fn(ctx, c)
+		if err == nil {
+			return nil
+		}
+		if !shouldFailover(err) {
+			return err
+		}
+
+		c.logger().WarnContext(ctx, "令牌不可用，尝试下一个令牌", slog.Int("index", i), slog.Any("err", err))
+		errs = append(errs, fmt.Errorf("第 %d 个令牌发送失败: %w", i+1, err))
+	}
+	return errors.Join(errs...)
+}
+
+// 函数判断错误是否需要转移到下一个令牌。
+func shouldFailover(err error) bool {
+	if errors.Is(err, ErrWebhookInvalid) {
+		return true
+	}
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
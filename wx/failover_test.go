@@ -0,0 +1,125 @@
+package wx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestFailoverClient_SendText(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	var tried []string
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		tried = append(tried, key)
+
+		switch key {
+		case "unavailable":
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		case "limited":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"errcode":45009,"errmsg":"api freq out of limit"}`))
+			return
+		case "invalid":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	testCases := []struct {
+		name  string   // 测试项目
+		keys  []string // 令牌列表
+		tried []string // 预期尝试的令牌
+		err   error    // 预期错误
+	}{
+		{
+			name:  "failover",
+			keys:  []string{"invalid", "unavailable", "ok", "unused"},
+			tried: []string{"invalid", "unavailable", "ok"},
+			err:   nil,
+		},
+		{
+			name:  "all failed",
+			keys:  []string{"invalid", "unavailable"},
+			tried: []string{"invalid", "unavailable"},
+			err:   ErrWebhookInvalid,
+		},
+		{
+			name:  "no failover",
+			keys:  []string{"limited", "ok"},
+			tried: []string{"limited"},
+			err:   APIError{Code: 45009, Message: "api freq out of limit"},
+		},
+		{
+			name:  "no keys",
+			keys:  nil,
+			tried: nil,
+			err:   ErrNeedToken,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tried = nil
+			f := FailoverClient{
+				BotClient: BotClient{
+					Client:  s.Client(),
+					Logger:  logger,
+					BaseURL: s.URL,
+				},
+				Keys: tc.keys,
+			}
+			err := f.SendText(context.Background(), "测试")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if !slices.Equal(tried, tc.tried) {
+				t.Fatalf("expect tried %v, got %v", tc.tried, tried)
+			}
+		})
+	}
+}
+
+func TestFailoverClient_ContextKey(t *testing.T) {
+	var tried []string
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		tried = append(tried, key)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if key == "invalid" {
+			w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	// ctx 中的令牌不应覆盖 Keys 中的令牌，否则每次尝试都会使用同一个令牌
+	f := FailoverClient{BotClient: base, Keys: []string{"invalid", "ok"}}
+	ctx := ContextWithKey(context.Background(), "invalid")
+	if err := f.SendText(ctx, "测试"); err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if !slices.Equal(tried, f.Keys) {
+		t.Fatalf("expect tried %v, got %v", f.Keys, tried)
+	}
+}
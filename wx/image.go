@@ -0,0 +1,103 @@
+package wx
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// 图片大小上限 (编码前)
+const maxImageSize = 2 << 20
+
+// 图片信息
+type ImageMessage struct {
+	Base64 string `json:"base64"` // 是	图片内容的base64编码
+	MD5    string `json:"md5"`    // 是	图片内容（base64编码前）的md5值
+}
+
+// 函数对图片内容编码，返回 base64 内容与小写十六进制的 md5 值。
+// 可用于直接构造 [ImageMessage]。
+func EncodeImage(img []byte) (base64Content, md5Hex string) {
+	sum := md5.Sum(img)
+	return base64.StdEncoding.EncodeToString(img), hex.EncodeToString(sum[:])
+}
+
+// 发送图片信息。
+// 图片最大不能超过 2MB，支持 JPG、PNG 格式，超过时返回 [ErrMediaTooLarge]。
+func (c BotClient) SendImage(ctx context.Context, img []byte) error {
+	c.logger().InfoContext(ctx, "发送图片消息", slog.Int("size", len(img)))
+
+	if len(img) > maxImageSize {
+		c.logger().ErrorContext(ctx, "图片过大")
+		return ErrMediaTooLarge
+	}
+
+	content, sum := EncodeImage(img)
+	return c.send(ctx, Message{
+		MsgType: MessageTypeImage,
+		Image:   &ImageMessage{Base64: content, MD5: sum},
+	})
+}
+
+// 发送 data URI 格式的图片，如前端生成的 data:image/png;base64,iVBORw0...。
+// 仅支持 base64 编码的 image/png 与 image/jpeg 图片，格式错误时返回描述原因的错误。
+// 解码后的图片超过 2MB 时返回 [ErrMediaTooLarge]。
+func (c BotClient) SendImageDataURI(ctx context.Context, dataURI string) error {
+	img, err := parseImageDataURI(dataURI)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "图片地址格式错误", slog.Any("err", err))
+		return err
+	}
+	return c.SendImage(ctx, img)
+}
+
+// 函数解析 base64 编码的图片 data URI，返回解码后的图片内容。
+func parseImageDataURI(dataURI string) ([]byte, error) {
+	rest, ok := strings.CutPrefix(dataURI, "data:")
+	if !ok {
+		return nil, errors.New("图片地址格式错误: 需要以 data: 开头")
+	}
+	meta, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return nil, errors.New("图片地址格式错误: 缺少逗号分隔的内容")
+	}
+	mime, ok := strings.CutSuffix(meta, ";base64")
+	if !ok {
+		return nil, errors.New("图片地址格式错误: 需要为 base64 编码")
+	}
+	switch strings.ToLower(mime) {
+	case "image/png", "image/jpeg", "image/jpg":
+	default:
+		return nil, fmt.Errorf("图片地址格式错误: 不支持的图片类型 %q, 需要为 image/png 或 image/jpeg", mime)
+	}
+
+	// 解码前检查长度，避免解码过大的内容
+	if base64.StdEncoding.DecodedLen(len(data)) > maxImageSize+2 {
+		return nil, ErrMediaTooLarge
+	}
+	img, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("图片地址格式错误: %w", err)
+	}
+	if len(img) > maxImageSize {
+		return nil, ErrMediaTooLarge
+	}
+	return img, nil
+}
+
+// 方法检查 md5 值与 base64 内容是否一致。
+func (m ImageMessage) check() error {
+	img, err := base64.StdEncoding.DecodeString(m.Base64)
+	if err != nil {
+		return ErrImageMismatch
+	}
+	if _, sum := EncodeImage(img); sum != m.MD5 {
+		return ErrImageMismatch
+	}
+	return nil
+}
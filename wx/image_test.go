@@ -0,0 +1,113 @@
+package wx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/kvii/bot/internal/errmatch"
+)
+
+func TestEncodeImage(t *testing.T) {
+	content, sum := EncodeImage([]byte("hello"))
+	if content != "aGVsbG8=" {
+		t.Fatalf("expect %q, got %q", "aGVsbG8=", content)
+	}
+	if sum != "5d41402abc4b2a76b9719d911017c592" {
+		t.Fatalf("expect %q, got %q", "5d41402abc4b2a76b9719d911017c592", sum)
+	}
+}
+
+func TestBotClient_SendImage(t *testing.T) {
+	var got Message
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	testCases := []struct {
+		name string       // 测试项目
+		send func() error // 发送函数
+		err  error        // 预期错误
+	}{
+		{
+			name: "normal",
+			send: func() error { return client.SendImage(context.Background(), []byte("hello")) },
+			err:  nil,
+		},
+		{
+			name: "too large",
+			send: func() error { return client.SendImage(context.Background(), make([]byte, maxImageSize+1)) },
+			err:  ErrMediaTooLarge,
+		},
+		{
+			name: "md5 mismatch",
+			send: func() error {
+				return client.Send(context.Background(), Message{
+					MsgType: MessageTypeImage,
+					Image:   &ImageMessage{Base64: "aGVsbG8=", MD5: "00000000000000000000000000000000"},
+				})
+			},
+			err: ErrImageMismatch,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.send()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+
+	if got.Image == nil || got.Image.MD5 != "5d41402abc4b2a76b9719d911017c592" {
+		t.Fatalf("unexpected image message: %+v", got.Image)
+	}
+}
+
+func TestBotClient_SendImageDataURI(t *testing.T) {
+	var got Message
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	// 1x1 的透明 png 图片
+	const png = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+	testCases := []struct {
+		name    string // 测试项目
+		dataURI string // 图片地址
+		err     error  // 预期错误
+	}{
+		{name: "png", dataURI: "data:image/png;base64," + png, err: nil},
+		{name: "not data uri", dataURI: "https://example.com/a.png", err: errmatch.Contains("需要以 data: 开头")},
+		{name: "missing comma", dataURI: "data:image/png;base64" + png, err: errmatch.Contains("缺少逗号")},
+		{name: "not base64 uri", dataURI: "data:image/png," + png, err: errmatch.Contains("需要为 base64 编码")},
+		{name: "unsupported type", dataURI: "data:image/gif;base64," + png, err: errmatch.Contains("不支持的图片类型")},
+		{name: "malformed base64", dataURI: "data:image/png;base64,!!!", err: errmatch.Contains("图片地址格式错误")},
+		{
+			name:    "too large",
+			dataURI: "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(make([]byte, maxImageSize+1)),
+			err:     ErrMediaTooLarge,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = Message{}
+			err := client.SendImageDataURI(context.Background(), tc.dataURI)
+			if !errors.Is(tc.err, err) && !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if tc.err == nil && (got.Image == nil || got.Image.Base64 != png) {
+				t.Fatalf("unexpected message: %+v", got)
+			}
+		})
+	}
+}
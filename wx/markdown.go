@@ -0,0 +1,144 @@
+package wx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// 字体颜色。markdown 信息只支持以下三种颜色。
+type FontColor string
+
+const (
+	FontColorInfo    FontColor = "info"    // 绿色
+	FontColorComment FontColor = "comment" // 灰色
+	FontColorWarning FontColor = "warning" // 橙红色
+)
+
+// 方法判断颜色是否为 markdown 信息支持的颜色。
+func (c FontColor) valid() bool {
+	switch c {
+	case FontColorInfo, FontColorComment, FontColorWarning:
+		return true
+	default:
+		return false
+	}
+}
+
+// markdown 内容构建器。
+// 只生成 markdown 信息支持的语法，生成的内容可以传给 [BotClient.SendMarkdown]。
+// 零值可以直接使用。
+type MarkdownBuilder struct {
+	b   strings.Builder
+	err error // 构建过程中的第一个错误
+}
+
+// 写入普通文本。
+func (m *MarkdownBuilder) Text(s string) *MarkdownBuilder {
+	m.b.WriteString(s)
+	return m
+}
+
+// 写入加粗文本。
+func (m *MarkdownBuilder) Bold(s string) *MarkdownBuilder {
+	fmt.Fprintf(&m.b, "**%s**", s)
+	return m
+}
+
+// 写入链接。
+func (m *MarkdownBuilder) Link(text, url string) *MarkdownBuilder {
+	fmt.Fprintf(&m.b, "[%s](%s)", text, url)
+	return m
+}
+
+// 写入带颜色的文本。
+// color 不是 [FontColorInfo]、[FontColorComment]、[FontColorWarning] 之一时只写入文本，
+// 并记录 [ErrUnsupportedColor]，可以通过 Err 方法获取。
+func (m *MarkdownBuilder) Color(color FontColor, s string) *MarkdownBuilder {
+	if !color.valid() {
+		if m.err == nil {
+			m.err = fmt.Errorf("%w: %q", ErrUnsupportedColor, color)
+		}
+		m.b.WriteString(s)
+		return m
+	}
+	fmt.Fprintf(&m.b, `<font color="%s">%s</font>`, color, s)
+	return m
+}
+
+// 写入文本并换行。s 为空时只换行。
+func (m *MarkdownBuilder) Line(s string) *MarkdownBuilder {
+	m.b.WriteString(s)
+	m.b.WriteString("\n")
+	return m
+}
+
+// 写入一行引用。
+func (m *MarkdownBuilder) Quote(s string) *MarkdownBuilder {
+	fmt.Fprintf(&m.b, "> %s\n", s)
+	return m
+}
+
+// 返回构建的 markdown 内容。
+func (m *MarkdownBuilder) String() string {
+	return m.b.String()
+}
+
+// 返回构建过程中的第一个错误，没有错误时返回 nil。
+func (m *MarkdownBuilder) Err() error {
+	return m.err
+}
+
+// 信息类型错误的错误码
+const codeInvalidMessageType = 40008
+
+// 发送 markdown 信息，接口不支持 markdown 时改为发送文本信息。
+// 部分会话中的机器人不支持 markdown，此时接口返回 40008 (信息类型错误)，
+// 方法会以 [StripMarkdown] 去除 markdown 语法后通过 [BotClient.SendText] 重新发送。
+// 文本信息的长度上限小于 markdown 信息，去除语法后的内容过长时返回 [ErrContentTooLong]。
+func (c BotClient) SendMarkdownWithFallback(ctx context.Context, md string) error {
+	err := c.SendMarkdown(ctx, md)
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != codeInvalidMessageType {
+		return err
+	}
+
+	c.logger().WarnContext(ctx, "不支持 markdown 信息，改为发送文本信息", slog.Any("err", err))
+	return c.SendText(ctx, StripMarkdown(md))
+}
+
+var (
+	mdLinkRegexp   = regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
+	mdBoldRegexp   = regexp.MustCompile(`\*\*(.*?)\*\*`)
+	mdHeaderRegexp = regexp.MustCompile(`(?m)^#{1,6}[ \t]+`)
+	mdFontRegexp   = regexp.MustCompile(`<font color="[^"]*">(.*?)</font>`)
+)
+
+// 函数去除 markdown 语法，返回纯文本。
+// 只处理 markdown 信息中常用的语法：链接转换为 "文本 (地址)"，去除加粗标记、标题标记与字体颜色标签。
+// 其他语法 (如引用、代码、列表) 原样保留。
+func StripMarkdown(s string) string {
+	s = mdLinkRegexp.ReplaceAllString(s, "$1 ($2)")
+	s = mdBoldRegexp.ReplaceAllString(s, "$1")
+	s = mdHeaderRegexp.ReplaceAllString(s, "")
+	s = mdFontRegexp.ReplaceAllString(s, "$1")
+	return s
+}
+
+// markdown 图片语法
+var mdImageRegexp = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+
+// 函数返回 markdown 信息不支持的语法内容：图片与表格的分隔行。markdown_v2 支持这些语法。
+func unsupportedMarkdown(s string) []string {
+	tokens := mdImageRegexp.FindAllString(s, -1)
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, "|") && strings.Contains(line, "-") && strings.Trim(line, "|-: \t") == "" {
+			tokens = append(tokens, line)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,224 @@
+package wx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownBuilder(t *testing.T) {
+	testCases := []struct {
+		name   string                   // 测试项目
+		build  func(m *MarkdownBuilder) // 构建过程
+		expect string                   // 预期内容
+		err    error                    // 预期错误
+	}{
+		{
+			name:   "text",
+			build:  func(m *MarkdownBuilder) { m.Text("测试") },
+			expect: "测试",
+		},
+		{
+			name:   "bold",
+			build:  func(m *MarkdownBuilder) { m.Bold("测试") },
+			expect: "**测试**",
+		},
+		{
+			name:   "link",
+			build:  func(m *MarkdownBuilder) { m.Link("链接", "https://work.weixin.qq.com") },
+			expect: "[链接](https://work.weixin.qq.com)",
+		},
+		{
+			name:   "color",
+			build:  func(m *MarkdownBuilder) { m.Color(FontColorWarning, "132例") },
+			expect: `<font color="warning">132例</font>`,
+		},
+		{
+			name:   "unsupported color",
+			build:  func(m *MarkdownBuilder) { m.Color("red", "132例").Color(FontColorInfo, "正常") },
+			expect: `132例<font color="info">正常</font>`,
+			err:    ErrUnsupportedColor,
+		},
+		{
+			name:   "line",
+			build:  func(m *MarkdownBuilder) { m.Line("第一行").Line("").Line("第三行") },
+			expect: "第一行\n\n第三行\n",
+		},
+		{
+			name:   "quote",
+			build:  func(m *MarkdownBuilder) { m.Quote("类型:用户反馈") },
+			expect: "> 类型:用户反馈\n",
+		},
+		{
+			name: "combined",
+			build: func(m *MarkdownBuilder) {
+				m.Text("实时新增用户反馈").Color(FontColorWarning, "132例").Line("，请相关同事注意。").
+					Quote("类型:"+`<font color="comment">用户反馈</font>`).
+					Bold("详情").Text(": ").Link("点击查看", "https://example.com")
+			},
+			expect: "实时新增用户反馈<font color=\"warning\">132例</font>，请相关同事注意。\n> 类型:<font color=\"comment\">用户反馈</font>\n**详情**: [点击查看](https://example.com)",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var m MarkdownBuilder
+			tc.build(&m)
+			if got := m.String(); got != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+			if err := m.Err(); !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	testCases := []struct {
+		name   string // 测试项目
+		md     string // markdown 内容
+		expect string // 预期内容
+	}{
+		{name: "plain", md: "测试", expect: "测试"},
+		{name: "link", md: "[链接](https://work.weixin.qq.com)", expect: "链接 (https://work.weixin.qq.com)"},
+		{name: "bold", md: "**加粗**与**加粗**", expect: "加粗与加粗"},
+		{name: "header", md: "# 标题\n## 二级标题\n正文 # 不是标题", expect: "标题\n二级标题\n正文 # 不是标题"},
+		{name: "color", md: `<font color="warning">132例</font>`, expect: "132例"},
+		{name: "quote kept", md: "> 引用", expect: "> 引用"},
+		{
+			name:   "mixed",
+			md:     "### **公告**\n1. [关于xxx的公告](https://example.com)",
+			expect: "公告\n1. 关于xxx的公告 (https://example.com)",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := StripMarkdown(tc.md); got != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestBotClient_SendMarkdownWithFallback(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	testCases := []struct {
+		name   string   // 测试项目
+		code   int      // markdown 信息的响应码
+		expect []string // 预期依次发送的信息类型
+		err    error    // 预期错误
+	}{
+		{name: "markdown", code: 0, expect: []string{MessageTypeMarkdown}},
+		{name: "fallback", code: 40008, expect: []string{MessageTypeMarkdown, MessageTypeText}},
+		{name: "other error", code: 93000, expect: []string{MessageTypeMarkdown}, err: ErrWebhookInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			var text string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var msg Message
+				json.NewDecoder(r.Body).Decode(&msg)
+				got = append(got, msg.MsgType)
+
+				code := 0
+				switch msg.MsgType {
+				case MessageTypeMarkdown:
+					code = tc.code
+				case MessageTypeText:
+					text = msg.Text.Content
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, `{"errcode":%d,"errmsg":"error"}`, code)
+			}))
+			t.Cleanup(s.Close)
+
+			client := BotClient{
+				Client:  s.Client(),
+				Logger:  logger,
+				BaseURL: s.URL,
+				Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+			}
+			err := client.SendMarkdownWithFallback(context.Background(), "**公告**: [详情](https://example.com)")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if !slices.Equal(got, tc.expect) {
+				t.Fatalf("expect %v, got %v", tc.expect, got)
+			}
+			if len(got) == 2 && text != "公告: 详情 (https://example.com)" {
+				t.Fatalf("unexpected text %q", text)
+			}
+		})
+	}
+}
+
+func TestBotClient_StrictMarkdown(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	var called bool
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	t.Cleanup(s.Close)
+
+	testCases := []struct {
+		name   string // 测试项目
+		strict bool   // 是否严格检查
+		md     string // markdown 内容
+		err    error  // 预期错误
+	}{
+		{name: "supported", strict: true, md: "**公告**\n> [详情](https://example.com)", err: nil},
+		{name: "image", strict: true, md: "图片: ![logo](https://example.com/logo.png)", err: ErrUnsupportedMarkdown},
+		{name: "table", strict: true, md: "| a | b |\n| :-- | --: |\n| 1 | 2 |", err: ErrUnsupportedMarkdown},
+		{name: "not strict", strict: false, md: "![logo](https://example.com/logo.png)", err: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			called = false
+			client := BotClient{
+				Client:         s.Client(),
+				Logger:         logger,
+				BaseURL:        s.URL,
+				Key:            "ee556a46-a3a7-4978-a186-7e3181f29da9",
+				StrictMarkdown: tc.strict,
+			}
+			err := client.SendMarkdown(context.Background(), tc.md)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if called != (tc.err == nil) {
+				t.Fatalf("expect request %t, got %t", tc.err == nil, called)
+			}
+		})
+	}
+
+	// 错误信息中列出不支持的内容
+	client := BotClient{Key: "ee556a46-a3a7-4978-a186-7e3181f29da9", Logger: logger, StrictMarkdown: true}
+	err := client.SendMarkdown(context.Background(), "![logo](https://example.com/logo.png)")
+	if !strings.Contains(err.Error(), "![logo](https://example.com/logo.png)") {
+		t.Fatalf("expect offending token in error, got %v", err)
+	}
+}
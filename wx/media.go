@@ -0,0 +1,105 @@
+package wx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// 文件类型
+type MediaType = string
+
+const (
+	MediaTypeFile  MediaType = "file"  // 普通文件
+	MediaTypeVoice MediaType = "voice" // 语音
+)
+
+// 文件大小上限
+const maxMediaSize = 20 << 20
+
+// 上传文件响应
+type UploadMediaResponse struct {
+	SendResponse
+	Type      MediaType `json:"type"`       // 文件类型，分别有语音(voice)和普通文件(file)
+	MediaID   string    `json:"media_id"`   // 媒体文件上传后获取的唯一标识，3天内有效
+	CreatedAt string    `json:"created_at"` // 媒体文件上传时间戳
+}
+
+// 上传文件，返回 media_id。
+// 文件大小不能超过 20MB，超过时返回 [ErrMediaTooLarge]。
+// media_id 仅三天内有效，且只能对发起上传的机器人可见。
+func (c BotClient) UploadMedia(ctx context.Context, name string, r io.Reader, mediaType MediaType) (string, error) {
+	c.logger().InfoContext(ctx, "上传文件", slog.String("name", name), slog.String("type", mediaType))
+	c = c.withContextKey(ctx)
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if c.Key == "" {
+		c.logger().ErrorContext(ctx, "需要提供令牌")
+		return "", ErrNeedToken
+	}
+
+	u, err := url.Parse(c.baseURL())
+	if err != nil {
+		c.logger().ErrorContext(ctx, "URL 解析失败", slog.Any("err", err))
+		return "", err
+	}
+	u = u.JoinPath("/cgi-bin/webhook/upload_media")
+	q := u.Query()
+	q.Set("key", c.Key)
+	q.Set("type", mediaType)
+	u.RawQuery = q.Encode()
+
+	content, err := io.ReadAll(io.LimitReader(r, maxMediaSize+1))
+	if err != nil {
+		c.logger().ErrorContext(ctx, "文件读取失败", slog.Any("err", err))
+		return "", err
+	}
+	if len(content) > maxMediaSize {
+		c.logger().ErrorContext(ctx, "文件过大")
+		return "", ErrMediaTooLarge
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("media", name)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "表单创建失败", slog.Any("err", err))
+		return "", err
+	}
+	_, err = fw.Write(content)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "表单写入失败", slog.Any("err", err))
+		return "", err
+	}
+	err = mw.Close()
+	if err != nil {
+		c.logger().ErrorContext(ctx, "表单写入失败", slog.Any("err", err))
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), &body)
+	if err != nil {
+		c.logger().ErrorContext(ctx, "请求创建失败", slog.Any("err", err))
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var data UploadMediaResponse
+	err = c.do(ctx, req, &data)
+	if err != nil {
+		return "", err
+	}
+	if data.ErrCode != 0 {
+		c.logger().ErrorContext(ctx, "响应异常", slog.Any("code", data.ErrCode), slog.String("msg", data.ErrMsg))
+		return "", APIError{Code: data.ErrCode, Message: data.ErrMsg}
+	}
+
+	c.logger().InfoContext(ctx, "文件上传成功", slog.String("mediaID", data.MediaID))
+	return data.MediaID, nil
+}
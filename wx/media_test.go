@@ -0,0 +1,74 @@
+package wx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBotClient_UploadMedia(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/upload_media", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		f, _, err := r.FormFile("media")
+		if err != nil {
+			w.Write([]byte(`{"errcode":44001,"errmsg":"empty media data"}`))
+			return
+		}
+		defer f.Close()
+
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok","type":"` + r.URL.Query().Get("type") + `","media_id":"3a8asd892asd8asd","created_at":"1380000000"}`))
+	})
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name    string    // 测试项目
+		r       io.Reader // 文件内容
+		mediaID string    // 预期 media_id
+		err     error     // 预期错误
+	}{
+		{
+			name:    "normal",
+			r:       strings.NewReader("测试文件内容"),
+			mediaID: "3a8asd892asd8asd",
+			err:     nil,
+		},
+		{
+			name:    "too large",
+			r:       bytes.NewReader(make([]byte, maxMediaSize+1)),
+			mediaID: "",
+			err:     ErrMediaTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mediaID, err := client.UploadMedia(context.Background(), "test.txt", tc.r, MediaTypeFile)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if mediaID != tc.mediaID {
+				t.Fatalf("expect %q, got %q", tc.mediaID, mediaID)
+			}
+			if mediaID == "" {
+				return
+			}
+
+			err = client.SendFile(context.Background(), mediaID)
+			if err != nil {
+				t.Fatalf("expect nil, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package wx
+
+import (
+	"context"
+	"sync"
+)
+
+// 默认最大并发数
+const defaultConcurrency = 4
+
+// 多机器人客户端。
+// 使用同一客户端配置，并发地向多个机器人发送相同的信息。
+type MultiClient struct {
+	BotClient   BotClient // 客户端配置。Key 字段会被 Keys 中的值替换。
+	Keys        []string  // 机器人令牌列表。
+	Concurrency int       // 最大并发数。不填则使用默认值 4。
+}
+
+// 向所有机器人发送文本信息。
+// 返回的错误列表与 Keys 按下标一一对应，发送成功的位置为 nil。
+func (m MultiClient) SendText(ctx context.Context, msg string) []error {
+	return m.each(ctx, func(ctx context.Context, c BotClient) error {
+		return c.SendText(ctx, msg)
+	})
+}
+
+// 向所有机器人发送信息。
+// 返回的错误列表与 Keys 按下标一一对应，发送成功的位置为 nil。
+func (m MultiClient) Send(ctx context.Context, msg Message) []error {
+	return m.each(ctx, func(ctx context.Context, c BotClient) error {
+		return c.Send(ctx, msg)
+	})
+}
+
+// 方法以有限的并发数对每个令牌对应的客户端执行 f。
+// ctx 结束后，尚未开始的发送直接返回 ctx.Err()。
+// ctx 中由 [ContextWithKey] 保存的令牌会被移除，以免覆盖 Keys 中的令牌。
+func (m MultiClient) each(ctx context.Context, f func(ctx context.Context, c BotClient) error) []error {
+	ctx = withoutContextKey(ctx)
+	errs := make([]error, len(m.Keys))
+	sem := make(chan struct{}, m.concurrency())
+
+	var wg sync.WaitGroup
+	for i, key := range m.Keys {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			c := m.BotClient
+			c.Key = key
+			errs[i] = f(ctx, c)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func (m MultiClient) concurrency() int {
+	if m.Concurrency <= 0 {
+		return defaultConcurrency
+	}
+	return m.Concurrency
+}
@@ -0,0 +1,116 @@
+package wx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMultiClient_SendText(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	var running, peak atomic.Int32
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Query().Get("key") == "invalid" {
+			w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	s := httptest.NewServer(&mux)
+	t.Cleanup(s.Close)
+
+	m := MultiClient{
+		BotClient: BotClient{
+			Client:  s.Client(),
+			Logger:  logger,
+			BaseURL: s.URL,
+		},
+		Keys:        []string{"a", "invalid", "b", "c", "", "d"},
+		Concurrency: 2,
+	}
+
+	errs := m.SendText(context.Background(), "测试")
+	if len(errs) != len(m.Keys) {
+		t.Fatalf("expect %d errors, got %d", len(m.Keys), len(errs))
+	}
+	for i, err := range errs {
+		switch m.Keys[i] {
+		case "invalid":
+			var apiErr APIError
+			if !errors.As(err, &apiErr) || apiErr.Code != 93000 {
+				t.Fatalf("keys[%d]: expect APIError 93000, got %v", i, err)
+			}
+		case "":
+			if !errors.Is(err, ErrNeedToken) {
+				t.Fatalf("keys[%d]: expect %v, got %v", i, ErrNeedToken, err)
+			}
+		default:
+			if err != nil {
+				t.Fatalf("keys[%d]: expect nil, got %v", i, err)
+			}
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Fatalf("expect concurrency <= 2, got %d", p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i, err := range m.SendText(ctx, "测试") {
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("keys[%d]: expect %v, got %v", i, context.Canceled, err)
+		}
+	}
+}
+
+func TestMultiClient_ContextKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	base, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.URL.Query().Get("key"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	// ctx 中的令牌不应覆盖 Keys 中的令牌
+	m := MultiClient{BotClient: base, Keys: []string{"a", "b"}}
+	ctx := ContextWithKey(context.Background(), "tenant")
+	for i, err := range m.SendText(ctx, "测试") {
+		if err != nil {
+			t.Fatalf("keys[%d]: expect nil, got %v", i, err)
+		}
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, m.Keys) {
+		t.Fatalf("expect keys %v, got %v", m.Keys, keys)
+	}
+}
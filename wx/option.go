@@ -0,0 +1,45 @@
+package wx
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// 客户端配置项
+type Option func(c *BotClient)
+
+// 设置底层 http client。
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *BotClient) { c.Client = client }
+}
+
+// 设置日志 logger。
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *BotClient) { c.Logger = logger }
+}
+
+// 设置接口基础地址。
+func WithBaseURL(baseURL string) Option {
+	return func(c *BotClient) { c.BaseURL = baseURL }
+}
+
+// 设置时间来源，仅用于测试。
+func withClock(now func() time.Time, sleep func(ctx context.Context, d time.Duration) error) Option {
+	return func(c *BotClient) { c.clock = clock{now: now, sleep: sleep} }
+}
+
+// 创建企业微信机器人客户端。
+// key 为空时返回 [ErrNeedToken]。未设置的配置项使用默认值。
+func NewBotClient(key string, opts ...Option) (BotClient, error) {
+	if key == "" {
+		return BotClient{}, ErrNeedToken
+	}
+
+	c := BotClient{Key: key}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c, nil
+}
@@ -0,0 +1,29 @@
+package wx
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+)
+
+func TestNewBotClient(t *testing.T) {
+	_, err := NewBotClient("")
+	if !errors.Is(err, ErrNeedToken) {
+		t.Fatalf("expect %v, got %v", ErrNeedToken, err)
+	}
+
+	client := &http.Client{}
+	logger := slog.Default()
+	c, err := NewBotClient("key",
+		WithHTTPClient(client),
+		WithLogger(logger),
+		WithBaseURL("http://localhost"),
+	)
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if c.Key != "key" || c.Client != client || c.Logger != logger || c.BaseURL != "http://localhost" {
+		t.Fatalf("unexpected client: %+v", c)
+	}
+}
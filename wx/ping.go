@@ -0,0 +1,74 @@
+package wx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// 内容为空的错误码
+const codeEmptyContent = 44004
+
+// 检查令牌格式。
+// 机器人令牌为 UUID 格式，如 693a91f6-7b1c-4bc4-97a0-0ec2e1fa5aaa，格式错误时返回 [ErrInvalidKey]。
+// 该方法只检查 Key 字段的格式，不会请求接口，也不读取 ctx 中通过 [ContextWithKey] 保存的令牌。
+func (c BotClient) ValidateKey() error {
+	if c.Key == "" {
+		return ErrNeedToken
+	}
+	if !isUUID(c.Key) {
+		return fmt.Errorf("%w: %q 不是 UUID 格式", ErrInvalidKey, c.Key)
+	}
+	return nil
+}
+
+// 检查机器人是否可用，不会在群中产生信息。
+// 方法先检查令牌格式，再发送一条内容为空的文本信息：
+// 令牌有效时接口返回 44004 (内容为空)，视为可用；令牌无效时返回对应的 [APIError]。
+// ctx 中通过 [ContextWithKey] 保存的令牌优先。检查请求不是真正的发送，
+// 因此不调用 BeforeSend、OnSend 与 OnError 钩子。
+func (c BotClient) Ping(ctx context.Context) error {
+	c.logger().InfoContext(ctx, "检查机器人")
+
+	c = c.withContextKey(ctx)
+	c.BeforeSend, c.OnSend, c.OnError = nil, nil, nil
+
+	if err := c.ValidateKey(); err != nil {
+		c.logger().ErrorContext(ctx, "令牌格式错误", slog.Any("err", err))
+		return err
+	}
+
+	_, err := c.SendRaw(ctx, Message{
+		MsgType: MessageTypeText,
+		Text:    &TextMessage{},
+	})
+	var apiErr APIError
+	if errors.As(err, &apiErr) && apiErr.Code == codeEmptyContent {
+		return nil
+	}
+	if err == nil {
+		return errors.New("响应异常: 空信息未被拒绝")
+	}
+	return err
+}
+
+// 函数判断字符串是否为 8-4-4-4-12 格式的 UUID。
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package wx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBotClient_ValidateKey(t *testing.T) {
+	testCases := []struct {
+		name string // 测试项目
+		key  string // 机器人令牌
+		err  error  // 预期错误
+	}{
+		{name: "normal", key: "ee556a46-a3a7-4978-a186-7e3181f29da9", err: nil},
+		{name: "upper case", key: "EE556A46-A3A7-4978-A186-7E3181F29DA9", err: nil},
+		{name: "empty", key: "", err: ErrNeedToken},
+		{name: "not hex", key: "7532a14a-d294-4a58-an57-6da300ecf68f", err: ErrInvalidKey},
+		{name: "too short", key: "ee556a46-a3a7-4978-a186", err: ErrInvalidKey},
+		{name: "wrong separator", key: "ee556a46_a3a7_4978_a186_7e3181f29da9", err: ErrInvalidKey},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := BotClient{Key: tc.key}.ValidateKey()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestBotClient_Ping(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		switch r.URL.Query().Get("key") {
+		case "ee556a46-a3a7-4978-a186-7e3181f29da9":
+			w.Write([]byte(`{"errcode":44004,"errmsg":"empty content"}`))
+		default:
+			w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+		}
+	})
+	base, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name string          // 测试项目
+		key  string          // 机器人令牌
+		ctx  context.Context // ctx 对象
+		err  error           // 预期错误
+	}{
+		{name: "normal", key: "ee556a46-a3a7-4978-a186-7e3181f29da9", ctx: context.Background(), err: nil},
+		{name: "invalid format", key: "invalid", ctx: context.Background(), err: ErrInvalidKey},
+		{name: "invalid webhook", key: "00000000-0000-0000-0000-000000000000", ctx: context.Background(), err: APIError{Code: 93000, Message: "invalid webhook url"}},
+		{name: "context key", key: "invalid", ctx: ContextWithKey(context.Background(), "ee556a46-a3a7-4978-a186-7e3181f29da9"), err: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var hooked bool
+			client := base
+			client.Key = tc.key
+			client.OnSend = func(msgType string, duration time.Duration, err error) {
+				hooked = true
+			}
+			client.OnError = func(ctx context.Context, msg Message, err error) {
+				hooked = true
+			}
+			err := client.Ping(tc.ctx)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if hooked {
+				t.Fatal("expect hooks not called")
+			}
+		})
+	}
+}
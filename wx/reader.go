@@ -0,0 +1,76 @@
+package wx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"unicode/utf8"
+)
+
+// 从 r 中读取 UTF-8 文本并发送。
+// 内容超过文本长度上限时，按字符边界分段依次发送，行为与 [BotClient.SendTextChunked] 相同，
+// 默认提醒成员 (@) 仅附加在第一段。
+// 内容不是合法的 UTF-8，或在结尾处截断了多字节字符时返回错误。
+// r 中没有内容，或第一段只有空白字符时返回 [ErrEmptyContent]，不发送任何信息。
+func (c BotClient) SendReader(ctx context.Context, r io.Reader) error {
+	c.logger().InfoContext(ctx, "发送文本流")
+
+	userIDs, mobiles := c.defaultMentions(ctx)
+
+	buf := make([]byte, MaxTextBytes)
+	n := 0 // buf 中已读取的字节数
+	for i := 0; ; i++ {
+		m, err := io.ReadFull(r, buf[n:])
+		n += m
+		eof := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !eof {
+			c.logger().ErrorContext(ctx, "文本读取失败", slog.Any("err", err))
+			return err
+		}
+
+		// 未读完时，在最后一个完整字符之后截断，剩余部分留到下一段
+		end := n
+		if !eof {
+			end = lastRuneEnd(buf[:n])
+		}
+		chunk := buf[:end]
+		if !utf8.Valid(chunk) {
+			if e := lastRuneEnd(chunk); eof && e < len(chunk) && utf8.Valid(chunk[:e]) {
+				c.logger().ErrorContext(ctx, "文本结尾字符不完整")
+				return errors.New("文本格式错误: 结尾处的多字节字符不完整")
+			}
+			c.logger().ErrorContext(ctx, "文本不是合法的 UTF-8")
+			return errors.New("文本格式错误: 不是合法的 UTF-8")
+		}
+
+		if i == 0 {
+			if err := c.checkEmpty(ctx, string(chunk)); err != nil {
+				return err
+			}
+		}
+		if len(chunk) > 0 {
+			if err := c.sendChunk(ctx, i, string(chunk), userIDs, mobiles); err != nil {
+				return err
+			}
+		}
+
+		if eof {
+			return nil
+		}
+		n = copy(buf, buf[end:n])
+	}
+}
+
+// 函数返回 b 中最后一个完整字符的结束位置。
+// 结尾的字符不完整时，返回该字符的起始位置。
+func lastRuneEnd(b []byte) int {
+	i := len(b) - 1
+	for i > 0 && !utf8.RuneStart(b[i]) {
+		i--
+	}
+	if i < 0 || utf8.FullRune(b[i:]) {
+		return len(b)
+	}
+	return i
+}
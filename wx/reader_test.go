@@ -0,0 +1,66 @@
+package wx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kvii/bot/internal/errmatch"
+)
+
+func TestBotClient_SendReader(t *testing.T) {
+	var got []string
+	var mentions [][]string
+	client, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		got = append(got, msg.Text.Content)
+		mentions = append(mentions, msg.Text.MentionedList)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	client.DefaultMentionedList = []string{"zhangsan"}
+
+	// 每个汉字 3 字节，2048 不是 3 的倍数，分段处会切在字符中间
+	long := strings.Repeat("测", 1000)
+
+	testCases := []struct {
+		name   string // 测试项目
+		input  string // 输入内容
+		chunks int    // 预期发送段数
+		err    error  // 预期错误
+	}{
+		{name: "short", input: "测试", chunks: 1, err: nil},
+		{name: "empty", input: "", chunks: 0, err: ErrEmptyContent},
+		{name: "blank", input: " \n", chunks: 0, err: ErrEmptyContent},
+		{name: "long", input: long, chunks: 2, err: nil},
+		{name: "partial rune", input: "测试"[:5], chunks: 0, err: errmatch.Contains("多字节字符不完整")},
+		{name: "invalid", input: "\xff测试", chunks: 0, err: errmatch.Contains("不是合法的 UTF-8")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, mentions = nil, nil
+			err := client.SendReader(context.Background(), strings.NewReader(tc.input))
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if len(got) != tc.chunks {
+				t.Fatalf("expect %d chunks, got %d", tc.chunks, len(got))
+			}
+			if tc.err == nil && strings.Join(got, "") != tc.input {
+				t.Fatal("chunks do not match input")
+			}
+			for i, m := range mentions {
+				if (i == 0) != slices.Equal(m, []string{"zhangsan"}) {
+					t.Fatalf("expect default mentions on the first chunk only, got %v", mentions)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package wx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// 模板卡片类型
+type CardType = string
+
+const (
+	CardTypeTextNotice CardType = "text_notice" // 文本通知模板卡片
+	CardTypeNewsNotice CardType = "news_notice" // 图文展示模板卡片
+)
+
+// 模板卡片
+type TemplateCard struct {
+	CardType        CardType             `json:"card_type"`                         // 是	模板卡片的模板类型
+	Source          *CardSource          `json:"source,omitempty"`                  // 否	卡片来源样式信息，不需要来源样式可不填写
+	MainTitle       CardTitle            `json:"main_title"`                        // 是	模版卡片的主要内容，包括一级标题和标题辅助信息
+	EmphasisContent *CardTitle           `json:"emphasis_content,omitempty"`        // 否	关键数据样式，建议不与引用样式共用。text_notice 使用。
+	CardImage       *CardImage           `json:"card_image,omitempty"`              // 否	图片样式。news_notice 使用。
+	SubTitleText    string               `json:"sub_title_text,omitempty"`          // 否	二级普通文本，建议不超过112个字。text_notice 使用。
+	HorizontalList  []CardHorizontalItem `json:"horizontal_content_list,omitempty"` // 否	二级标题+文本列表，列表长度不超过6
+	JumpList        []CardJump           `json:"jump_list,omitempty"`               // 否	跳转指引样式的列表，列表长度不超过3
+	CardAction      CardAction           `json:"card_action"`                       // 是	整体卡片的点击跳转事件
+}
+
+// 卡片来源
+type CardSource struct {
+	IconURL   string `json:"icon_url,omitempty"`   // 否	来源图片的url
+	Desc      string `json:"desc,omitempty"`       // 否	来源图片的描述，建议不超过13个字
+	DescColor int    `json:"desc_color,omitempty"` // 否	来源文字的颜色，0(默认) 灰色，1 黑色，2 红色，3 绿色
+}
+
+// 卡片标题
+type CardTitle struct {
+	Title string `json:"title,omitempty"` // 否	标题
+	Desc  string `json:"desc,omitempty"`  // 否	辅助信息
+}
+
+// 卡片图片
+type CardImage struct {
+	URL         string  `json:"url"`                    // 是	图片的url
+	AspectRatio float64 `json:"aspect_ratio,omitempty"` // 否	图片的宽高比，宽高比要小于2.25，大于1.3，不填该参数默认1.3
+}
+
+// 卡片二级标题+文本
+type CardHorizontalItem struct {
+	Type    int    `json:"type,omitempty"`     // 否	链接类型，0 普通文本，1 跳转url，2 下载附件，3 @员工
+	KeyName string `json:"keyname"`            // 是	二级标题，建议不超过5个字
+	Value   string `json:"value,omitempty"`    // 否	二级文本
+	URL     string `json:"url,omitempty"`      // 否	链接跳转的url，type 为 1 时必填
+	MediaID string `json:"media_id,omitempty"` // 否	附件的 media_id，type 为 2 时必填
+	UserID  string `json:"userid,omitempty"`   // 否	被@的成员的userid，type 为 3 时必填
+}
+
+// 卡片跳转指引
+type CardJump struct {
+	Type     int    `json:"type,omitempty"`     // 否	跳转链接类型，0 不跳转，1 跳转url，2 跳转小程序
+	Title    string `json:"title"`              // 是	跳转链接样式的文案内容，建议不超过13个字
+	URL      string `json:"url,omitempty"`      // 否	跳转链接的url，type 为 1 时必填
+	AppID    string `json:"appid,omitempty"`    // 否	跳转链接的小程序的appid，type 为 2 时必填
+	PagePath string `json:"pagepath,omitempty"` // 否	跳转链接的小程序的pagepath
+}
+
+// 卡片点击跳转类型
+type CardActionType = int
+
+const (
+	CardActionTypeURL         CardActionType = 1 // 跳转url
+	CardActionTypeMiniProgram CardActionType = 2 // 打开小程序
+)
+
+// 卡片点击跳转事件
+type CardAction struct {
+	Type     CardActionType `json:"type"`               // 是	卡片跳转类型，1 跳转url，2 打开小程序
+	URL      string         `json:"url,omitempty"`      // 否	跳转事件的url，type 为 1 时必填
+	AppID    string         `json:"appid,omitempty"`    // 否	跳转事件的小程序的appid，type 为 2 时必填
+	PagePath string         `json:"pagepath,omitempty"` // 否	跳转事件的小程序的pagepath
+}
+
+// 发送模板卡片信息。
+// 卡片类型需要为 [CardTypeTextNotice] 或 [CardTypeNewsNotice]。
+func (c BotClient) SendTemplateCard(ctx context.Context, card TemplateCard) error {
+	c.logger().InfoContext(ctx, "发送模板卡片消息", slog.String("cardType", card.CardType))
+
+	if card.CardType != CardTypeTextNotice && card.CardType != CardTypeNewsNotice {
+		c.logger().ErrorContext(ctx, "模板卡片类型错误", slog.String("cardType", card.CardType))
+		return fmt.Errorf("模板卡片类型错误: %q, 需要为 %s 或 %s", card.CardType, CardTypeTextNotice, CardTypeNewsNotice)
+	}
+
+	return c.send(ctx, Message{
+		MsgType:      MessageTypeTemplateCard,
+		TemplateCard: &card,
+	})
+}
@@ -0,0 +1,64 @@
+package wx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/kvii/bot/internal/errmatch"
+)
+
+func TestBotClient_SendTemplateCard(t *testing.T) {
+	var got []byte
+	var mux http.ServeMux
+	mux.HandleFunc("POST /cgi-bin/webhook/send", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	client, _ := newTestServer(t, &mux)
+
+	testCases := []struct {
+		name   string       // 测试项目
+		card   TemplateCard // 模板卡片
+		expect string       // 预期请求内容
+		err    error        // 预期错误
+	}{
+		{
+			name: "text notice",
+			card: TemplateCard{
+				CardType:   CardTypeTextNotice,
+				MainTitle:  CardTitle{Title: "欢迎使用企业微信", Desc: "您的好友正在邀请您加入企业微信"},
+				CardAction: CardAction{Type: CardActionTypeURL, URL: "https://work.weixin.qq.com"},
+			},
+			expect: `{"msgtype":"template_card","template_card":{"card_type":"text_notice","main_title":{"title":"欢迎使用企业微信","desc":"您的好友正在邀请您加入企业微信"},"card_action":{"type":1,"url":"https://work.weixin.qq.com"}}}`,
+			err:    nil,
+		},
+		{
+			name: "invalid card type",
+			card: TemplateCard{
+				CardType:   "button_interaction",
+				MainTitle:  CardTitle{Title: "欢迎使用企业微信"},
+				CardAction: CardAction{Type: CardActionTypeURL, URL: "https://work.weixin.qq.com"},
+			},
+			expect: "",
+			err:    errmatch.Contains("模板卡片类型错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := client.SendTemplateCard(context.Background(), tc.card)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if string(got) != tc.expect {
+				t.Fatalf("expect %s, got %s", tc.expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package wx
+
+import (
+	"time"
+
+	"github.com/kvii/bot/internal/tracker"
+)
+
+// 发送记录，保存最近一次发送成功的时间。实现与其他平台的客户端共用。
+type SendTracker = tracker.SendTracker
+
+// 方法返回最近一次发送成功的时间。未设置 Tracker 或尚未发送成功时返回零值。
+func (c BotClient) LastSentAt() time.Time {
+	return c.Tracker.LastSentAt()
+}
@@ -0,0 +1,65 @@
+package wx
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBotClient_LastSentAt(t *testing.T) {
+	var logger *slog.Logger
+	if testing.Verbose() {
+		logger = slog.Default()
+	} else {
+		logger = NopLogger()
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	t.Cleanup(s.Close)
+
+	// 未设置 Tracker 时返回零值
+	if got := (BotClient{}).LastSentAt(); !got.IsZero() {
+		t.Fatalf("expect zero time, got %v", got)
+	}
+
+	client := BotClient{
+		Client:  s.Client(),
+		Logger:  logger,
+		BaseURL: s.URL,
+		Key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+		Tracker: new(SendTracker),
+	}
+	if got := client.LastSentAt(); !got.IsZero() {
+		t.Fatalf("expect zero time, got %v", got)
+	}
+
+	// 并发发送与读取，需要配合 -race 运行
+	before := time.Now()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := client.SendText(context.Background(), "测试"); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			client.LastSentAt()
+		}()
+	}
+	wg.Wait()
+
+	if got := client.LastSentAt(); got.Before(before) {
+		t.Fatalf("expect last sent after %v, got %v", before, got)
+	}
+}
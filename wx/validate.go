@@ -0,0 +1,75 @@
+package wx
+
+import "fmt"
+
+// 方法检查信息是否合法，不会产生任何副作用。
+// 检查内容包括：信息类型是否支持、对应的内容字段是否存在、内容长度是否超出上限。
+// 发送前会自动调用该方法。
+func (m Message) Validate() error {
+	switch m.MsgType {
+	case MessageTypeText:
+		if m.Text == nil {
+			return missingField(m.MsgType, "Text")
+		}
+		return checkBytes(m.Text.Content, MaxTextBytes)
+	case MessageTypeMarkdown:
+		if m.Markdown == nil {
+			return missingField(m.MsgType, "Markdown")
+		}
+		return checkBytes(m.Markdown.Content, MaxMarkdownBytes)
+	case MessageTypeMarkdownV2:
+		if m.MarkdownV2 == nil {
+			return missingField(m.MsgType, "MarkdownV2")
+		}
+		return checkBytes(m.MarkdownV2.Content, MaxMarkdownBytes)
+	case MessageTypeImage:
+		if m.Image == nil {
+			return missingField(m.MsgType, "Image")
+		}
+		return m.Image.check()
+	case MessageTypeNews:
+		if m.News == nil {
+			return missingField(m.MsgType, "News")
+		}
+		if n := len(m.News.Articles); n < 1 || n > 8 {
+			return fmt.Errorf("图文数量错误: %d, 需要在 1 到 8 条之间", n)
+		}
+		return nil
+	case MessageTypeFile:
+		if m.File == nil {
+			return missingField(m.MsgType, "File")
+		}
+		return nil
+	case MessageTypeVoice:
+		if m.Voice == nil {
+			return missingField(m.MsgType, "Voice")
+		}
+		if m.Voice.MediaID == "" {
+			return ErrEmptyMediaID
+		}
+		return nil
+	case MessageTypeTemplateCard:
+		if m.TemplateCard == nil {
+			return missingField(m.MsgType, "TemplateCard")
+		}
+		if t := m.TemplateCard.CardType; t != CardTypeTextNotice && t != CardTypeNewsNotice {
+			return fmt.Errorf("模板卡片类型错误: %q, 需要为 %s 或 %s", t, CardTypeTextNotice, CardTypeNewsNotice)
+		}
+		return nil
+	default:
+		return fmt.Errorf("信息类型错误: %q", m.MsgType)
+	}
+}
+
+// 函数返回内容字段缺失的错误。
+func missingField(msgType MessageType, field string) error {
+	return fmt.Errorf("信息内容为空: %s 信息需要提供 %s 字段", msgType, field)
+}
+
+// 函数检查内容长度，超过上限时返回 [ErrContentTooLong]。
+func checkBytes(content string, limit int) error {
+	if n := len(content); n > limit {
+		return fmt.Errorf("%w: %d 字节, 上限 %d 字节", ErrContentTooLong, n, limit)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package wx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kvii/bot/internal/errmatch"
+)
+
+func TestMessage_Validate(t *testing.T) {
+	testCases := []struct {
+		name string  // 测试项目
+		msg  Message // 信息
+		err  error   // 预期错误
+	}{
+		{
+			name: "text",
+			msg:  Message{MsgType: MessageTypeText, Text: &TextMessage{Content: "测试"}},
+			err:  nil,
+		},
+		{
+			name: "text too long",
+			msg:  Message{MsgType: MessageTypeText, Text: &TextMessage{Content: strings.Repeat("a", MaxTextBytes+1)}},
+			err:  ErrContentTooLong,
+		},
+		{
+			name: "markdown too long",
+			msg:  Message{MsgType: MessageTypeMarkdown, Markdown: &MarkdownMessage{Content: strings.Repeat("a", MaxMarkdownBytes+1)}},
+			err:  ErrContentTooLong,
+		},
+		{
+			name: "missing field",
+			msg:  Message{MsgType: MessageTypeMarkdownV2, Markdown: &MarkdownMessage{Content: "测试"}},
+			err:  errmatch.Contains("信息内容为空"),
+		},
+		{
+			name: "unknown type",
+			msg:  Message{MsgType: "video"},
+			err:  errmatch.Contains("信息类型错误"),
+		},
+		{
+			name: "news count",
+			msg:  Message{MsgType: MessageTypeNews, News: &NewsMessage{}},
+			err:  errmatch.Contains("图文数量错误"),
+		},
+		{
+			name: "image mismatch",
+			msg:  Message{MsgType: MessageTypeImage, Image: &ImageMessage{Base64: "aGVsbG8=", MD5: "0"}},
+			err:  ErrImageMismatch,
+		},
+		{
+			name: "template card type",
+			msg:  Message{MsgType: MessageTypeTemplateCard, TemplateCard: &TemplateCard{CardType: "unknown"}},
+			err:  errmatch.Contains("模板卡片类型错误"),
+		},
+		{
+			name: "file",
+			msg:  Message{MsgType: MessageTypeFile, File: &FileMessage{MediaID: "id"}},
+			err:  nil,
+		},
+		{
+			name: "voice",
+			msg:  Message{MsgType: MessageTypeVoice, Voice: &VoiceMessage{MediaID: "id"}},
+			err:  nil,
+		},
+		{
+			name: "voice missing field",
+			msg:  Message{MsgType: MessageTypeVoice},
+			err:  errmatch.Contains("信息内容为空"),
+		},
+		{
+			name: "voice empty media id",
+			msg:  Message{MsgType: MessageTypeVoice, Voice: &VoiceMessage{}},
+			err:  ErrEmptyMediaID,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.msg.Validate()
+			if !errors.Is(tc.err, err) && !errors.Is(err, tc.err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package wx
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// 函数解析完整的 webhook 地址，如 https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=xxx。
+// 返回的 baseURL 为协议与主机部分，key 为 key 查询参数。缺少 key 参数时返回错误。
+func ParseWebhook(fullURL string) (baseURL, key string, err error) {
+	baseURL, _, key, err = parseWebhook(fullURL)
+	return baseURL, key, err
+}
+
+// 函数根据完整的 webhook 地址创建客户端，见 [ParseWebhook]。
+// 地址的路径与默认值不同时，会设置为客户端的 WebhookPath。
+func NewBotClientFromURL(fullURL string) (BotClient, error) {
+	baseURL, path, key, err := parseWebhook(fullURL)
+	if err != nil {
+		return BotClient{}, err
+	}
+	c := BotClient{BaseURL: baseURL, Key: key}
+	if path != c.webhookPath() {
+		c.WebhookPath = path
+	}
+	return c, nil
+}
+
+// 函数解析完整的 webhook 地址，返回基础地址、路径与令牌。
+func parseWebhook(fullURL string) (baseURL, path, key string, err error) {
+	u, err := url.Parse(fullURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("webhook 地址错误: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", "", "", fmt.Errorf("webhook 地址错误: %q 缺少协议或主机", fullURL)
+	}
+
+	key = u.Query().Get("key")
+	if key == "" {
+		return "", "", "", errors.New("webhook 地址错误: 缺少 key 参数")
+	}
+	return u.Scheme + "://" + u.Host, u.Path, key, nil
+}
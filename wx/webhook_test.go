@@ -0,0 +1,70 @@
+package wx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kvii/bot/internal/errmatch"
+)
+
+func TestParseWebhook(t *testing.T) {
+	testCases := []struct {
+		name    string // 测试项目
+		fullURL string // 完整地址
+		baseURL string // 预期基础地址
+		key     string // 预期令牌
+		err     error  // 预期错误
+	}{
+		{
+			name:    "normal",
+			fullURL: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=ee556a46-a3a7-4978-a186-7e3181f29da9",
+			baseURL: "https://qyapi.weixin.qq.com",
+			key:     "ee556a46-a3a7-4978-a186-7e3181f29da9",
+		},
+		{
+			name:    "missing key",
+			fullURL: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send",
+			err:     errmatch.Contains("缺少 key 参数"),
+		},
+		{
+			name:    "key only",
+			fullURL: "ee556a46-a3a7-4978-a186-7e3181f29da9",
+			err:     errmatch.Contains("缺少协议或主机"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			baseURL, key, err := ParseWebhook(tc.fullURL)
+			if !errors.Is(tc.err, err) {
+				t.Fatalf("expect %v, got %v", tc.err, err)
+			}
+			if baseURL != tc.baseURL || key != tc.key {
+				t.Fatalf("expect %q %q, got %q %q", tc.baseURL, tc.key, baseURL, key)
+			}
+		})
+	}
+}
+
+func TestNewBotClientFromURL(t *testing.T) {
+	c, err := NewBotClientFromURL("https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=xxx")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if c.BaseURL != "https://qyapi.weixin.qq.com" || c.Key != "xxx" || c.WebhookPath != "" {
+		t.Fatalf("unexpected client: %+v", c)
+	}
+
+	// 自定义网关路径
+	c, err = NewBotClientFromURL("https://gateway.example.com/wx/send?key=xxx")
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	if c.WebhookPath != "/wx/send" {
+		t.Fatalf("expect webhook path %q, got %q", "/wx/send", c.WebhookPath)
+	}
+
+	_, err = NewBotClientFromURL("https://qyapi.weixin.qq.com/cgi-bin/webhook/send")
+	if err == nil {
+		t.Fatal("expect error, got nil")
+	}
+}